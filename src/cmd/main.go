@@ -10,8 +10,10 @@ import (
 	"github.com/sk/elective/src/internal/config"
 	"github.com/sk/elective/src/internal/delivery"
 	"github.com/sk/elective/src/internal/repository"
+	"github.com/sk/elective/src/internal/repository/models"
 	"github.com/sk/elective/src/internal/usecase"
 	"github.com/sk/elective/src/pkg/database"
+	"github.com/sk/elective/src/pkg/notifier"
 )
 
 func main() {
@@ -34,28 +36,62 @@ func main() {
 
 	// Initialize repositories
 	studentRepo := repository.NewStudentRepository(db)
+	rosterRepo := repository.NewRosterRepository(db)
+	categoryRepo := repository.NewElectiveCategoryRepository(db)
+	termRepo := repository.NewAcademicTermRepository(db)
+	windowRepo := repository.NewBookingWindowRepository(db)
+	phaseRepo := repository.NewBookingPhaseRepository(db)
+	preferenceRepo := repository.NewCoursePreferenceRepository(db)
 	courseRepo := repository.NewCourseRepository(db)
 	bookingRepo := repository.NewCourseBookingRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	unitOfWork := repository.NewUnitOfWork(db)
+
+	// Initialize notifier
+	studentNotifier, err := notifier.New(cfg.Notifier, cfg.IsDevelopment())
+	if err != nil {
+		log.Fatal("Invalid notifier configuration:", err)
+	}
 
 	// Initialize usecase
-	authService := usecase.NewAuthService(studentRepo, cfg.JWT)
-	courseService := usecase.NewCourseService(courseRepo, bookingRepo)
+	authService, err := usecase.NewAuthService(studentRepo, rosterRepo, refreshTokenRepo, loginAttemptRepo, unitOfWork, studentNotifier, cfg.JWT, cfg.Auth)
+	if err != nil {
+		log.Fatal("Invalid JWT configuration:", err)
+	}
+	if err := authService.BootstrapAdmins(); err != nil {
+		log.Fatal("Failed to bootstrap admins:", err)
+	}
+	rosterService := usecase.NewRosterService(rosterRepo, unitOfWork, cfg.Departments)
+	categoryService := usecase.NewElectiveCategoryService(categoryRepo, unitOfWork)
+	termService := usecase.NewTermService(termRepo, windowRepo, phaseRepo, unitOfWork)
+	courseService, err := usecase.NewCourseService(categoryRepo, termRepo, windowRepo, phaseRepo, courseRepo, bookingRepo, waitlistRepo, unitOfWork, cfg.Booking)
+	if err != nil {
+		log.Fatal("Invalid booking configuration:", err)
+	}
+	allocationService, err := usecase.NewAllocationService(termRepo, preferenceRepo, unitOfWork, cfg.Booking)
+	if err != nil {
+		log.Fatal("Invalid booking configuration:", err)
+	}
 
 	// Initialize delivery
-	authHandler := delivery.NewAuthHandler(authService)
-	courseHandler := delivery.NewCourseHandler(courseService)
+	validator := delivery.NewValidator(cfg.Departments)
+	authHandler := delivery.NewAuthHandler(authService, validator)
+	categoryHandler := delivery.NewCategoryHandler(categoryService, validator)
+	termHandler := delivery.NewTermHandler(termService, validator)
+	courseHandler := delivery.NewCourseHandler(courseService, validator)
+	allocationHandler := delivery.NewAllocationHandler(allocationService, validator)
+	rosterHandler := delivery.NewRosterHandler(rosterService)
 
 	// Initialize Fiber app
+	// Login throttling keys on c.IP(), so it must be the real client address
 	app := fiber.New(fiber.Config{
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			code := fiber.StatusInternalServerError
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-			}
-			return c.Status(code).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		},
+		ErrorHandler:            delivery.ErrorHandler,
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
 	})
 
 	app.Use(cors.New(cors.Config{
@@ -72,6 +108,11 @@ func main() {
 	auth := api.Group("/auth")
 	auth.Post("/register", authHandler.Register)
 	auth.Post("/login", authHandler.Login)
+	auth.Post("/refresh", authHandler.Refresh)
+	auth.Post("/logout", authHandler.Logout)
+	auth.Post("/forgot-password", authHandler.ForgotPassword)
+	auth.Post("/reset-password", authHandler.ResetPassword)
+	auth.Post("/change-password", authHandler.AuthMiddleware, authHandler.ChangePassword)
 	auth.Get("/validate", authHandler.ValidateToken) // Add this line
 
 	// Protected routes
@@ -80,11 +121,51 @@ func main() {
 	// Course routes
 	courses := protected.Group("/courses")
 
-	courses.Post("/", courseHandler.CreateCourse)
+	staffOnly := authHandler.RequireRole(models.RoleStaff, models.RoleAdmin)
+	adminOnly := authHandler.RequireRole(models.RoleAdmin)
+
+	courses.Post("/", staffOnly, courseHandler.CreateCourse)
 	courses.Get("/available", courseHandler.GetAvailableCourses)
 	courses.Post("/book", courseHandler.BookCourse)
+	courses.Post("/swap", courseHandler.SwapCourse)
 	courses.Get("/my-bookings", courseHandler.GetMyBookings)
+	courses.Get("/my-timetable", courseHandler.GetMyTimetable)
 	courses.Get("/all", courseHandler.GetAllCourses)
+	courses.Get("/categories", categoryHandler.GetCategories)
+	courses.Get("/terms", termHandler.GetTerms)
+	courses.Get("/preferences", allocationHandler.GetPreferences)
+	courses.Put("/preferences", allocationHandler.SubmitPreferences)
+	courses.Delete("/bookings/:id", courseHandler.CancelBooking)
+	courses.Post("/:id/waitlist", courseHandler.JoinWaitlist)
+	courses.Get("/:id/waitlist", courseHandler.GetWaitlistPosition)
+	courses.Delete("/:id/waitlist", courseHandler.LeaveWaitlist)
+	courses.Get("/:id", courseHandler.GetCourse)
+	courses.Patch("/:id", staffOnly, courseHandler.UpdateCourse)
+	courses.Delete("/:id", staffOnly, courseHandler.DeleteCourse)
+
+	// Admin routes
+	admin := protected.Group("/admin", staffOnly)
+	admin.Delete("/bookings/:id", courseHandler.AdminCancelBooking)
+	admin.Put("/students/:register_no/role", adminOnly, authHandler.SetRole)
+	admin.Get("/students/:register_no/lock", authHandler.GetLoginLock)
+	admin.Delete("/students/:register_no/lock", adminOnly, authHandler.UnlockAccount)
+	admin.Get("/roster", rosterHandler.GetRoster)
+	admin.Post("/roster/import", adminOnly, rosterHandler.ImportRoster)
+	admin.Post("/categories", adminOnly, categoryHandler.CreateCategory)
+	admin.Patch("/categories/:id", adminOnly, categoryHandler.UpdateCategory)
+	admin.Delete("/categories/:id", adminOnly, categoryHandler.DeleteCategory)
+	admin.Post("/terms", adminOnly, termHandler.CreateTerm)
+	admin.Patch("/terms/:id", adminOnly, termHandler.UpdateTerm)
+	admin.Get("/terms/:id/windows", termHandler.GetWindows)
+	admin.Post("/terms/:id/windows", adminOnly, termHandler.CreateWindow)
+	admin.Delete("/terms/:id/windows/:window_id", adminOnly, termHandler.DeleteWindow)
+	admin.Get("/terms/:id/phases", termHandler.GetPhases)
+	admin.Post("/terms/:id/phases", adminOnly, termHandler.CreatePhase)
+	admin.Delete("/terms/:id/phases/:phase_id", adminOnly, termHandler.DeletePhase)
+	admin.Post("/terms/:id/allocation", adminOnly, allocationHandler.RunAllocation)
+
+	// Public keys for verifying our tokens
+	app.Get("/.well-known/jwks.json", authHandler.JWKS)
 
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
@@ -1,13 +1,31 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Database DataBaseConfig
 	JWT      JWTConfig
+	Auth     AuthConfig
 	Server   ServerConfig
+	Booking  BookingConfig
+	Notifier NotifierConfig
+	// Departments lists the department codes requests may refer to.
+	Departments []string
+	// Environment is "development" on developer machines; anything else,
+	// including the default "production", turns off development aids such
+	// as the log notifier.
+	Environment string
+}
+
+// IsDevelopment reports whether the server runs on a developer machine.
+func (c *Config) IsDevelopment() bool {
+	return c.Environment == "development"
 }
 
 type DataBaseConfig struct {
@@ -19,11 +37,96 @@ type DataBaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret     string
+	Secret          string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// Algorithm is the only signing algorithm accepted: HS256 (default),
+	// RS256 or EdDSA.
+	Algorithm string
+	// ActiveKeyID is the kid of the key new tokens are signed with.
+	ActiveKeyID string
+	// Keys holds the active key and any retired keys that tokens may still
+	// be verified with. For HS256 the material is the shared secret, for
+	// RS256 and EdDSA it is the path to a PEM file. Retired asymmetric keys
+	// may be public keys.
+	Keys []JWTKey
+}
+
+type JWTKey struct {
+	ID       string
+	Material string
+}
+
+type AuthConfig struct {
+	// PasswordResetTTL is how long a password reset token stays valid.
+	PasswordResetTTL time.Duration
+	// MaxResetRequests limits password reset requests per register number,
+	// and IPMaxResetRequests per client address, within PasswordResetTTL.
+	MaxResetRequests   int
+	IPMaxResetRequests int
+
+	// MaxFailedLogins locks an account after that many consecutive failed
+	// logins. IPMaxFailedLogins blocks a client address after that many
+	// failures across all accounts; it is set high because students behind
+	// a campus NAT share an address.
+	MaxFailedLogins   int
+	IPMaxFailedLogins int
+	// LockoutDuration is how long a lock lasts. Failures older than this
+	// are forgotten.
+	LockoutDuration time.Duration
+	// LoginBaseDelay is the wait imposed on an account after its first
+	// failure; it doubles with every further failure up to LockoutDuration.
+	LoginBaseDelay time.Duration
+
+	// AdminRegisterNos bootstraps administrators. These register numbers may
+	// register without a roster entry and get the admin role, and existing
+	// accounts among them are promoted to admin at startup. Set it to create
+	// the first admin, who can then import the roster and assign roles.
+	AdminRegisterNos []string
+
+	Password PasswordPolicyConfig
+}
+
+type PasswordPolicyConfig struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// DenylistFile optionally names a file of extra forbidden passwords,
+	// one per line, on top of the built-in list of common ones.
+	DenylistFile string
+}
+
+type NotifierConfig struct {
+	// Driver picks how students are notified: "log" (default) writes
+	// messages, reset tokens included, to the log and is only allowed in
+	// development; "none" sends nothing, so password resets are unavailable.
+	Driver string
+	// OutboxFile, when set, makes the development notifier append messages
+	// to this file instead of writing them to the log.
+	OutboxFile string
 }
 
 type ServerConfig struct {
 	Port string
+	// ProxyHeader names the header, such as X-Forwarded-For, that carries
+	// the client address when the server runs behind a proxy. It is only
+	// trusted on requests from TrustedProxies, which lists proxy addresses
+	// or CIDR ranges; without them the connection's address is used.
+	ProxyHeader    string
+	TrustedProxies []string
+}
+
+type BookingConfig struct {
+	// SeatStrategy picks the seat for bookings that do not name one:
+	// "lowest" (default), "random" or "department-block".
+	SeatStrategy string
+	// DropDeadline is the last moment students may cancel their own
+	// bookings of courses outside any term; terms set their own. The zero
+	// value means there is no deadline.
+	DropDeadline time.Time
 }
 
 func LoadConfig() *Config {
@@ -36,11 +139,48 @@ func LoadConfig() *Config {
 			DBName:   getEnv("DB_NAME"),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET"),
+			Secret:          getEnv("JWT_SECRET"),
+			AccessTokenTTL:  getEnvDuration("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL: getEnvDuration("JWT_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			Algorithm:       getEnvDefault("JWT_ALGORITHM", "HS256"),
+			ActiveKeyID:     getEnvDefault("JWT_ACTIVE_KID", "default"),
+			Keys:            getEnvJWTKeys("JWT_KEYS"),
+		},
+		Auth: AuthConfig{
+			PasswordResetTTL:   getEnvDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+			MaxResetRequests:   getEnvInt("PASSWORD_RESET_MAX_REQUESTS", 3),
+			IPMaxResetRequests: getEnvInt("PASSWORD_RESET_IP_MAX_REQUESTS", 50),
+			MaxFailedLogins:    getEnvInt("LOGIN_MAX_FAILED_ATTEMPTS", 5),
+			IPMaxFailedLogins:  getEnvInt("LOGIN_IP_MAX_FAILED_ATTEMPTS", 200),
+			LockoutDuration:    getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			LoginBaseDelay:     getEnvDuration("LOGIN_BASE_DELAY", time.Second),
+			AdminRegisterNos:   getEnvList("ADMIN_REGISTER_NOS", nil),
+			Password: PasswordPolicyConfig{
+				MinLength:     getEnvInt("PASSWORD_MIN_LENGTH", 8),
+				RequireUpper:  getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+				RequireLower:  getEnvBool("PASSWORD_REQUIRE_LOWER", true),
+				RequireDigit:  getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+				RequireSymbol: getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+				DenylistFile:  getEnv("PASSWORD_DENYLIST_FILE"),
+			},
 		},
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT"),
+			Port:           getEnv("SERVER_PORT"),
+			ProxyHeader:    getEnv("PROXY_HEADER"),
+			TrustedProxies: getEnvList("TRUSTED_PROXIES", nil),
+		},
+		Booking: BookingConfig{
+			SeatStrategy: getEnv("SEAT_ASSIGNMENT_STRATEGY"),
+			DropDeadline: getEnvTime("DROP_DEADLINE"),
+		},
+		Notifier: NotifierConfig{
+			Driver:     getEnvDefault("NOTIFIER", "log"),
+			OutboxFile: getEnv("NOTIFIER_OUTBOX_FILE"),
 		},
+		Departments: getEnvList("DEPARTMENTS", []string{
+			"CSE", "IT", "ECE", "EEE", "EIE", "MECH", "CIVIL", "CHEM", "BME", "AIDS", "AIML", "CSBS",
+		}),
+		Environment: getEnvDefault("APP_ENV", "production"),
 	}
 }
 
@@ -48,3 +188,108 @@ func getEnv(key string) string {
 
 	return os.Getenv(key)
 }
+
+func getEnvDefault(key, fallback string) string {
+	if value := getEnv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvInt parses a positive integer, returning fallback when the variable
+// is unset or malformed.
+func getEnvInt(key string, fallback int) int {
+	value := getEnv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Warning: ignoring %s, expected a positive integer", key)
+		return fallback
+	}
+	return n
+}
+
+// getEnvBool parses a boolean such as "true" or "0", returning fallback when
+// the variable is unset or malformed.
+func getEnvBool(key string, fallback bool) bool {
+	value := getEnv(key)
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: ignoring %s, expected true or false", key)
+		return fallback
+	}
+	return b
+}
+
+// getEnvList parses a comma separated list, returning fallback when the
+// variable is unset.
+func getEnvList(key string, fallback []string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
+
+// getEnvJWTKeys parses a comma separated list of kid=material pairs.
+func getEnvJWTKeys(key string) []JWTKey {
+	var keys []JWTKey
+	for _, pair := range strings.Split(getEnv(key), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, material, ok := strings.Cut(pair, "=")
+		if !ok || id == "" || material == "" {
+			log.Printf("Warning: ignoring malformed entry in %s, expected kid=value", key)
+			continue
+		}
+		keys = append(keys, JWTKey{ID: id, Material: material})
+	}
+	return keys
+}
+
+// getEnvTime parses an RFC 3339 timestamp, returning the zero time when the
+// variable is unset or malformed.
+func getEnvTime(key string) time.Time {
+	value := getEnv(key)
+	if value == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Printf("Warning: ignoring %s, expected an RFC 3339 timestamp: %v", key, err)
+		return time.Time{}
+	}
+	return t
+}
+
+// getEnvDuration parses a Go duration such as "15m", returning fallback when
+// the variable is unset or malformed.
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := getEnv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Warning: ignoring %s, expected a positive duration such as 15m", key)
+		return fallback
+	}
+	return d
+}
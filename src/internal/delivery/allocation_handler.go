@@ -0,0 +1,96 @@
+package delivery
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+type AllocationHandler struct {
+	allocationService domain.AllocationService
+	validator         *Validator
+}
+
+func NewAllocationHandler(allocationService domain.AllocationService, validator *Validator) *AllocationHandler {
+	return &AllocationHandler{allocationService: allocationService, validator: validator}
+}
+
+// SubmitPreferencesRequest ranks courses of one category, first choice
+// first. An empty list withdraws the category's preferences. TermID defaults
+// to the active term.
+type SubmitPreferencesRequest struct {
+	TermID     uint   `json:"term_id"`
+	CategoryID uint   `json:"category_id" validate:"required"`
+	CourseIDs  []uint `json:"course_ids" validate:"max=20,dive,required"`
+}
+
+type GetPreferencesRequest struct {
+	TermID uint `query:"term_id" json:"term_id"`
+}
+
+type RunAllocationRequest struct {
+	Seed   int64 `json:"seed"`
+	Commit bool  `json:"commit"`
+}
+
+func (h *AllocationHandler) GetPreferences(c *fiber.Ctx) error {
+	student := c.Locals("student").(*models.Student)
+
+	var req GetPreferencesRequest
+	if err := h.validator.ParseQuery(c, &req); err != nil {
+		return err
+	}
+
+	preferences, err := h.allocationService.GetPreferences(student.ID, req.TermID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"preferences": preferences,
+	})
+}
+
+func (h *AllocationHandler) SubmitPreferences(c *fiber.Ctx) error {
+	student := c.Locals("student").(*models.Student)
+
+	var req SubmitPreferencesRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	preferences, err := h.allocationService.SubmitPreferences(student, req.TermID, req.CategoryID, req.CourseIDs)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message":     "Preferences saved successfully",
+		"preferences": preferences,
+	})
+}
+
+// RunAllocation runs the preference allocation of a term. It is a dry run
+// that only reports the outcome unless commit is set.
+func (h *AllocationHandler) RunAllocation(c *fiber.Ctx) error {
+	termID, err := c.ParamsInt("id")
+	if err != nil || termID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid term id")
+	}
+
+	var req RunAllocationRequest
+	if len(c.Body()) > 0 {
+		if err := h.validator.ParseBody(c, &req); err != nil {
+			return err
+		}
+	}
+
+	report, err := h.allocationService.RunAllocation(uint(termID), req.Seed, req.Commit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"report": report,
+	})
+}
@@ -3,24 +3,30 @@ package delivery
 
 import (
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
 )
 
 type AuthHandler struct {
 	authService domain.AuthService
+	validator   *Validator
 }
 
-func NewAuthHandler(authService domain.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+func NewAuthHandler(authService domain.AuthService, validator *Validator) *AuthHandler {
+	return &AuthHandler{authService: authService, validator: validator}
 }
 
 type RegisterRequest struct {
-	RegisterNo string `json:"register_no" validate:"required"`
-	Password   string `json:"password" validate:"required,min=6"`
-	Name       string `json:"name" validate:"required,min=6"`
-	Department string `json:"department"`
+	RegisterNo string `json:"register_no" validate:"required,max=32"`
+	Password   string `json:"password" validate:"required"`
+	Name       string `json:"name" validate:"required,min=2,max=100"`
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=student staff admin"`
 }
 
 type LoginRequest struct {
@@ -28,9 +34,29 @@ type LoginRequest struct {
 	Password   string `json:"password" validate:"required"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	RegisterNo string `json:"register_no" validate:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
 type AuthResponse struct {
-	Token   string      `json:"token"`
-	Student interface{} `json:"student"`
+	Token        string      `json:"token"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+	RefreshToken string      `json:"refresh_token"`
+	Student      interface{} `json:"student"`
 }
 
 type ValidateResponse struct {
@@ -40,21 +66,13 @@ type ValidateResponse struct {
 
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req RegisterRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
 	}
 
-	if req.Department == "" {
-		req.Department = "CSE"
-	}
-
-	student, err := h.authService.Register(req.RegisterNo, req.Password, req.Department, req.Name)
+	student, err := h.authService.Register(req.RegisterNo, req.Password, req.Name)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -63,52 +81,131 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 			"id":          student.ID,
 			"register_no": student.RegisterNo,
 			"department":  student.Department,
+			"batch":       student.Batch,
+			"semester":    student.Semester,
+			"cgpa":        student.CGPA,
 			"name":        student.Name,
+			"role":        student.Role,
 		},
 	})
 }
 
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
 	}
 
-	token, student, err := h.authService.Login(req.RegisterNo, req.Password)
+	tokens, student, err := h.authService.Login(req.RegisterNo, req.Password, c.IP())
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(AuthResponse{
-		Token: token,
+		Token:        tokens.AccessToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		RefreshToken: tokens.RefreshToken,
 		Student: fiber.Map{
 			"id":          student.ID,
 			"register_no": student.RegisterNo,
 			"department":  student.Department,
+			"batch":       student.Batch,
+			"semester":    student.Semester,
+			"cgpa":        student.CGPA,
 			"name":        student.Name,
+			"role":        student.Role,
 		},
 	})
 }
 
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req RefreshRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	tokens, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(tokens)
+}
+
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req RefreshRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.authService.Logout(req.RefreshToken); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Logged out successfully",
+	})
+}
+
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req ForgotPasswordRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.authService.ForgotPassword(req.RegisterNo, c.IP()); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "If the register number exists, a password reset token has been sent",
+	})
+}
+
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.authService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Password reset successfully, please log in again",
+	})
+}
+
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	student := c.Locals("student").(*models.Student)
+
+	var req ChangePasswordRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	err := h.authService.ChangePassword(student.ID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Password changed successfully, please log in again",
+	})
+}
+
 // Add this new method for token validation
 func (h *AuthHandler) ValidateToken(c *fiber.Ctx) error {
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization header required",
-		})
+		return domain.ErrUnauthorized.WithMessage("Authorization header required")
 	}
 
 	tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
 	student, err := h.authService.ValidateToken(tokenString)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid or expired token",
-		})
+		return err
 	}
 
 	return c.JSON(ValidateResponse{
@@ -117,27 +214,102 @@ func (h *AuthHandler) ValidateToken(c *fiber.Ctx) error {
 			"id":          student.ID,
 			"register_no": student.RegisterNo,
 			"department":  student.Department,
+			"batch":       student.Batch,
+			"semester":    student.Semester,
+			"cgpa":        student.CGPA,
 			"name":        student.Name,
+			"role":        student.Role,
 		},
 	})
 }
 
+func (h *AuthHandler) JWKS(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"keys": h.authService.JWKS(),
+	})
+}
+
 func (h *AuthHandler) AuthMiddleware(c *fiber.Ctx) error {
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization header required",
-		})
+		return domain.ErrUnauthorized.WithMessage("Authorization header required")
 	}
 
 	tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
 	student, err := h.authService.ValidateToken(tokenString)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
+		return err
 	}
 
 	c.Locals("student", student)
 	return c.Next()
-}
\ No newline at end of file
+}
+
+// RequireRole only lets requests through when the student set by
+// AuthMiddleware has one of the given roles.
+func (h *AuthHandler) RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		student, ok := c.Locals("student").(*models.Student)
+		if !ok {
+			return domain.ErrUnauthorized.WithMessage("Authorization header required")
+		}
+
+		for _, role := range roles {
+			if student.Role == role {
+				return c.Next()
+			}
+		}
+
+		return domain.ErrForbidden
+	}
+}
+
+func (h *AuthHandler) SetRole(c *fiber.Ctx) error {
+	var req SetRoleRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	student, err := h.authService.SetRole(c.Params("register_no"), req.Role)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Role updated successfully",
+		"student": fiber.Map{
+			"id":          student.ID,
+			"register_no": student.RegisterNo,
+			"department":  student.Department,
+			"batch":       student.Batch,
+			"semester":    student.Semester,
+			"cgpa":        student.CGPA,
+			"name":        student.Name,
+			"role":        student.Role,
+		},
+	})
+}
+
+func (h *AuthHandler) GetLoginLock(c *fiber.Ctx) error {
+	attempt, err := h.authService.GetLoginLock(c.Params("register_no"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"register_no":     c.Params("register_no"),
+		"locked":          attempt.IsLocked(time.Now()),
+		"locked_until":    attempt.LockedUntil,
+		"failed_attempts": attempt.Failures,
+	})
+}
+
+func (h *AuthHandler) UnlockAccount(c *fiber.Ctx) error {
+	if err := h.authService.UnlockAccount(c.Params("register_no")); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Account unlocked successfully",
+	})
+}
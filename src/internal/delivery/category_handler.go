@@ -0,0 +1,110 @@
+package delivery
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+type CategoryHandler struct {
+	categoryService domain.ElectiveCategoryService
+	validator       *Validator
+}
+
+func NewCategoryHandler(categoryService domain.ElectiveCategoryService, validator *Validator) *CategoryHandler {
+	return &CategoryHandler{categoryService: categoryService, validator: validator}
+}
+
+type CreateCategoryRequest struct {
+	Name          string `json:"name" validate:"required"`
+	MaxSelections int    `json:"max_selections" validate:"required,min=1"`
+	Semesters     []int  `json:"semesters" validate:"dive,min=1,max=12"`
+}
+
+// UpdateCategoryRequest is a partial update; omitted fields keep their value.
+type UpdateCategoryRequest struct {
+	Name          *string `json:"name" validate:"omitempty,min=1"`
+	MaxSelections *int    `json:"max_selections" validate:"omitempty,min=1"`
+	Semesters     *[]int  `json:"semesters" validate:"omitempty,dive,min=1,max=12"`
+}
+
+func (h *CategoryHandler) GetCategories(c *fiber.Ctx) error {
+	categories, err := h.categoryService.GetCategories()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"categories": categories,
+	})
+}
+
+func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
+	var req CreateCategoryRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	category := &models.ElectiveCategory{
+		Name:          req.Name,
+		MaxSelections: req.MaxSelections,
+		Semesters:     models.IntArray(req.Semesters),
+	}
+	if err := h.categoryService.CreateCategory(category); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":  "Category created successfully",
+		"category": category,
+	})
+}
+
+func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
+	categoryID, err := c.ParamsInt("id")
+	if err != nil || categoryID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid category id")
+	}
+
+	var req UpdateCategoryRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	update := &models.ElectiveCategoryUpdate{
+		Name:          req.Name,
+		MaxSelections: req.MaxSelections,
+	}
+	if req.Semesters != nil {
+		semesters := models.IntArray(*req.Semesters)
+		if semesters == nil {
+			semesters = models.IntArray{}
+		}
+		update.Semesters = &semesters
+	}
+
+	category, err := h.categoryService.UpdateCategory(uint(categoryID), update)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Category updated successfully",
+		"category": category,
+	})
+}
+
+func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
+	categoryID, err := c.ParamsInt("id")
+	if err != nil || categoryID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid category id")
+	}
+
+	if err := h.categoryService.DeleteCategory(uint(categoryID)); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Category deleted successfully",
+	})
+}
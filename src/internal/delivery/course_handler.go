@@ -1,31 +1,84 @@
 package delivery
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/sk/elective/src/internal/domain"
 	"github.com/sk/elective/src/internal/repository/models"
+	"github.com/sk/elective/src/pkg/ical"
 )
 
 type CourseHandler struct {
 	courseService domain.CourseService
+	validator     *Validator
 }
 
-func NewCourseHandler(courseService domain.CourseService) *CourseHandler {
-	return &CourseHandler{courseService: courseService}
+func NewCourseHandler(courseService domain.CourseService, validator *Validator) *CourseHandler {
+	return &CourseHandler{courseService: courseService, validator: validator}
 }
 
 type CreateCourseRequest struct {
 	Name           string   `json:"name" validate:"required"`
-	PDFLink        string   `json:"pdf_link"`
-	Rating         float64  `json:"rating"`
+	PDFLink        string   `json:"pdf_link" validate:"omitempty,url"`
+	Rating         float64  `json:"rating" validate:"min=0,max=5"`
 	StaffNames     []string `json:"staff_names"`
-	ImageLink      string   `json:"image_link"`
+	ImageLink      string   `json:"image_link" validate:"omitempty,url"`
 	Description    string   `json:"description"`
 	AvailableSeats int      `json:"available_seats"`
-	Departments    []string `json:"departments" validate:"required"`
+	Departments    []string `json:"departments" validate:"required,min=1,dive,department"`
 	Genres         []string `json:"genres"`
-	CourseType     int      `json:"course_type" validate:"required,min=1,max=4"`
+	CourseType     int      `json:"course_type" validate:"required,min=1"`
+	TermID         uint     `json:"term_id"`
 	TotalSeats     int      `json:"total_seats" validate:"required,min=1"`
+
+	DepartmentQuotas map[string]int `json:"department_quotas" validate:"omitempty,dive,keys,department,endkeys,min=0"`
+	QuotaReleaseAt   *time.Time     `json:"quota_release_at"`
+
+	Prerequisites   []int   `json:"prerequisites" validate:"dive,min=1"`
+	ExcludedCourses []int   `json:"excluded_courses" validate:"dive,min=1"`
+	MinSemester     int     `json:"min_semester" validate:"min=0"`
+	MinCGPA         float64 `json:"min_cgpa" validate:"min=0,max=10"`
+
+	Sessions []SessionRequest `json:"sessions" validate:"dive"`
+}
+
+// UpdateCourseRequest is a partial update; omitted fields keep their value.
+type UpdateCourseRequest struct {
+	Name        *string   `json:"name" validate:"omitempty,min=1"`
+	PDFLink     *string   `json:"pdf_link" validate:"omitempty,url"`
+	Rating      *float64  `json:"rating" validate:"omitempty,min=0,max=5"`
+	StaffNames  *[]string `json:"staff_names"`
+	ImageLink   *string   `json:"image_link" validate:"omitempty,url"`
+	Description *string   `json:"description"`
+	Departments *[]string `json:"departments" validate:"omitempty,min=1,dive,department"`
+	Genres      *[]string `json:"genres"`
+	CourseType  *int      `json:"course_type" validate:"omitempty,min=1"`
+	TermID      *uint     `json:"term_id"`
+	TotalSeats  *int      `json:"total_seats" validate:"omitempty,min=1"`
+
+	DepartmentQuotas *map[string]int `json:"department_quotas" validate:"omitempty,dive,keys,department,endkeys,min=0"`
+	// QuotaReleaseAt is cleared by an explicit null, keeping the quotas for
+	// good.
+	QuotaReleaseAt models.NullableTime `json:"quota_release_at"`
+
+	Prerequisites   *[]int   `json:"prerequisites" validate:"omitempty,dive,min=1"`
+	ExcludedCourses *[]int   `json:"excluded_courses" validate:"omitempty,dive,min=1"`
+	MinSemester     *int     `json:"min_semester" validate:"omitempty,min=0"`
+	MinCGPA         *float64 `json:"min_cgpa" validate:"omitempty,min=0,max=10"`
+
+	Sessions *[]SessionRequest `json:"sessions" validate:"omitempty,dive"`
+}
+
+// SessionRequest is one weekly meeting of a course. Sessions without a
+// section are attended by every section.
+type SessionRequest struct {
+	Section string `json:"section" validate:"max=20"`
+	Day     string `json:"day" validate:"required,oneof=monday tuesday wednesday thursday friday saturday sunday"`
+	Start   string `json:"start" validate:"required,datetime=15:04"`
+	End     string `json:"end" validate:"required,datetime=15:04"`
+	Room    string `json:"room"`
 }
 
 type CourseResponse struct {
@@ -39,14 +92,131 @@ type CourseResponse struct {
 	Departments    []string `json:"departments"`
 	Genres         []string `json:"genres"`
 	CourseType     int      `json:"course_type"`
+	TermID         uint     `json:"term_id"`
 	TotalSeats     int      `json:"total_seats"`
 	SeatsBooked    []string `json:"seats_booked"`
 	AvailableSeats int      `json:"available_seats"`
+	Status         string   `json:"status"`
+
+	DepartmentQuotas map[string]int `json:"department_quotas"`
+	QuotaReleaseAt   *time.Time     `json:"quota_release_at,omitempty"`
+
+	Prerequisites   []int   `json:"prerequisites"`
+	ExcludedCourses []int   `json:"excluded_courses"`
+	MinSemester     int     `json:"min_semester"`
+	MinCGPA         float64 `json:"min_cgpa"`
+
+	Sessions []models.Session `json:"sessions"`
+}
+
+const defaultPageLimit = 20
+
+type ListCoursesRequest struct {
+	Search     string `query:"q" json:"q"`
+	Genre      string `query:"genre" json:"genre"`
+	Department string `query:"department" json:"department" validate:"omitempty,department"`
+	CourseType int    `query:"course_type" json:"course_type" validate:"omitempty,min=1"`
+	TermID     *uint  `query:"term_id" json:"term_id"`
+	Staff      string `query:"staff" json:"staff"`
+	Available  string `query:"available" json:"available" validate:"omitempty,oneof=true false"`
+	Sort       string `query:"sort" json:"sort" validate:"omitempty,oneof=rating name seats_left"`
+	Order      string `query:"order" json:"order" validate:"omitempty,oneof=asc desc"`
+	Page       int    `query:"page" json:"page" validate:"omitempty,min=1"`
+	Limit      int    `query:"limit" json:"limit" validate:"omitempty,min=1,max=100"`
 }
 
+func (r ListCoursesRequest) toQuery() models.CourseQuery {
+	query := models.CourseQuery{
+		Search:     r.Search,
+		Genre:      r.Genre,
+		Department: r.Department,
+		CourseType: r.CourseType,
+		TermID:     r.TermID,
+		Staff:      r.Staff,
+		Sort:       r.Sort,
+		Desc:       r.Order == "desc",
+		Page:       r.Page,
+		Limit:      r.Limit,
+	}
+	if r.Available != "" {
+		available := r.Available == "true"
+		query.Available = &available
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultPageLimit
+	}
+	return query
+}
+
+type PaginationResponse struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+func toCoursePageResponse(page *models.CoursePage) fiber.Map {
+	response := make([]CourseResponse, 0, len(page.Courses))
+	for _, course := range page.Courses {
+		response = append(response, toCourseResponse(course))
+	}
+
+	limit := int64(page.Limit)
+	return fiber.Map{
+		"courses": response,
+		"pagination": PaginationResponse{
+			Page:       page.Page,
+			Limit:      page.Limit,
+			Total:      page.Total,
+			TotalPages: (page.Total + limit - 1) / limit,
+		},
+	}
+}
+
+type BookingScheduleResponse struct {
+	*models.BookingSchedule
+	Open bool `json:"open"`
+}
+
+type CourseDetailResponse struct {
+	CourseResponse
+	SeatMap          []models.Seat    `json:"seat_map"`
+	BookingsByDept   map[string]int64 `json:"bookings_by_department"`
+	WaitlistLength   int64            `json:"waitlist_length"`
+	Booked           bool             `json:"booked"`
+	Eligible         bool             `json:"eligible"`
+	IneligibleReason string           `json:"ineligible_reason,omitempty"`
+
+	Quotas        []QuotaUsageResponse `json:"quota_usage"`
+	QuotaReleased bool                 `json:"quota_released"`
+}
+
+type QuotaUsageResponse struct {
+	Department string `json:"department"`
+	Quota      int    `json:"quota"`
+	Booked     int64  `json:"booked"`
+	Remaining  int64  `json:"remaining"`
+}
+
+const (
+	CourseStatusOpen = "open"
+	CourseStatusFull = "full"
+)
+
 type BookCourseRequest struct {
 	CourseID uint   `json:"course_id" validate:"required"`
-	SeatNo   string `json:"seat_no" validate:"required"`
+	SeatNo   string `json:"seat_no" validate:"omitempty,numeric"`
+	Section  string `json:"section"`
+}
+
+type SwapCourseRequest struct {
+	CourseID      uint   `json:"course_id" validate:"required"`
+	SeatNo        string `json:"seat_no" validate:"omitempty,numeric"`
+	FromBookingID uint   `json:"from_booking_id"`
+	Section       string `json:"section"`
 }
 
 func toCourseResponse(course models.Course) CourseResponse {
@@ -61,64 +231,220 @@ func toCourseResponse(course models.Course) CourseResponse {
 		Departments:    []string(course.Departments),
 		Genres:         []string(course.Genres),
 		CourseType:     course.CourseType,
+		TermID:         course.TermID,
 		TotalSeats:     course.TotalSeats,
 		SeatsBooked:    []string(course.SeatsBooked),
-		AvailableSeats: course.TotalSeats - len(course.SeatsBooked),
+		AvailableSeats: course.SeatsLeft(),
+		Status:         courseStatus(course),
+
+		DepartmentQuotas: map[string]int(course.DepartmentQuotas),
+		QuotaReleaseAt:   course.QuotaReleaseAt,
+
+		Prerequisites:   []int(course.Prerequisites),
+		ExcludedCourses: []int(course.ExcludedCourses),
+		MinSemester:     course.MinSemester,
+		MinCGPA:         course.MinCGPA,
+
+		Sessions: []models.Session(course.Sessions),
+	}
+}
+
+func courseStatus(course models.Course) string {
+	if course.IsFull() {
+		return CourseStatusFull
 	}
+	return CourseStatusOpen
 }
 
 func (h *CourseHandler) GetAvailableCourses(c *fiber.Ctx) error {
 	student := c.Locals("student").(*models.Student)
 
-	courses, err := h.courseService.GetAvailableCourses(student.ID, student.Department)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	var req ListCoursesRequest
+	if err := h.validator.ParseQuery(c, &req); err != nil {
+		return err
 	}
 
-	var response []CourseResponse
-	for _, course := range courses {
-		response = append(response, toCourseResponse(course))
+	page, err := h.courseService.GetAvailableCourses(student, req.toQuery())
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(fiber.Map{
-		"courses": response,
-	})
+	response := toCoursePageResponse(page)
+	if page.Schedule != nil {
+		response["booking_schedule"] = BookingScheduleResponse{
+			BookingSchedule: page.Schedule,
+			Open:            page.Schedule.IsOpen(time.Now()),
+		}
+	}
+	return c.JSON(response)
 }
 
 func (h *CourseHandler) GetAllCourses(c *fiber.Ctx) error {
-	courses, err := h.courseService.GetAllCourses()
+	var req ListCoursesRequest
+	if err := h.validator.ParseQuery(c, &req); err != nil {
+		return err
+	}
+
+	page, err := h.courseService.GetAllCourses(req.toQuery())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
-	var response []CourseResponse
-	for _, course := range courses {
-		response = append(response, toCourseResponse(course))
+	return c.JSON(toCoursePageResponse(page))
+}
+
+type TimetableRequest struct {
+	TermID uint   `query:"term_id" json:"term_id"`
+	Format string `query:"format" json:"format" validate:"omitempty,oneof=json ics"`
+}
+
+type TimetableDayResponse struct {
+	Day      string                     `json:"day"`
+	Sessions []TimetableSessionResponse `json:"sessions"`
+}
+
+type TimetableSessionResponse struct {
+	CourseID   uint   `json:"course_id"`
+	CourseName string `json:"course_name"`
+	SeatNo     string `json:"seat_no"`
+	Section    string `json:"section,omitempty"`
+	Start      string `json:"start"`
+	End        string `json:"end"`
+	Room       string `json:"room"`
+}
+
+// GetMyTimetable returns the student's weekly timetable grouped by day, or
+// with format=ics as an iCalendar file of weekly events over the term.
+func (h *CourseHandler) GetMyTimetable(c *fiber.Ctx) error {
+	student := c.Locals("student").(*models.Student)
+
+	var req TimetableRequest
+	if err := h.validator.ParseQuery(c, &req); err != nil {
+		return err
+	}
+
+	timetable, err := h.courseService.GetTimetable(student.ID, req.TermID)
+	if err != nil {
+		return err
+	}
+
+	if req.Format == "ics" {
+		now := time.Now()
+		c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="timetable.ics"`)
+		return c.Send(timetableCalendar(student, timetable, now).Encode(now))
 	}
 
 	return c.JSON(fiber.Map{
-		"courses": response,
+		"term":      timetable.Term,
+		"timetable": toTimetableResponse(timetable.Entries),
 	})
 }
 
-func (h *CourseHandler) CreateCourse(c *fiber.Ctx) error {
-	var req CreateCourseRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
+// toTimetableResponse groups the entries, which are already in order, by day.
+func toTimetableResponse(entries []models.TimetableEntry) []TimetableDayResponse {
+	days := []TimetableDayResponse{}
+	for _, entry := range entries {
+		if len(days) == 0 || days[len(days)-1].Day != entry.Day {
+			days = append(days, TimetableDayResponse{Day: entry.Day, Sessions: []TimetableSessionResponse{}})
+		}
+		day := &days[len(days)-1]
+		day.Sessions = append(day.Sessions, TimetableSessionResponse{
+			CourseID:   entry.CourseID,
+			CourseName: entry.CourseName,
+			SeatNo:     entry.SeatNo,
+			Section:    entry.Section,
+			Start:      entry.Start,
+			End:        entry.End,
+			Room:       entry.Room,
 		})
 	}
+	return days
+}
+
+// timetableCalendar turns each session into a weekly event running from the
+// start of the term to its end, or from now on for courses outside any term.
+func timetableCalendar(student *models.Student, timetable *models.Timetable, now time.Time) *ical.Calendar {
+	calendar := &ical.Calendar{
+		ProdID: "-//Elective//Timetable//EN",
+		Name:   "Timetable",
+	}
+
+	from := now
+	var until time.Time
+	if timetable.Term != nil {
+		calendar.Name = "Timetable " + timetable.Term.Name
+		from = timetable.Term.StartsAt
+		until = timetable.Term.EndsAt
+	}
 
-	// Validate course type
-	if req.CourseType != 1 && req.CourseType != 2 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Course type must be 1 or 2",
+	for _, entry := range timetable.Entries {
+		start, end, ok := entry.Minutes()
+		if !ok || entry.DayIndex() < 0 {
+			continue
+		}
+		date := firstWeekday(from, entry.DayIndex())
+		summary := entry.CourseName
+		if entry.Section != "" {
+			summary += " (" + entry.Section + ")"
+		}
+
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:         fmt.Sprintf("%s-%d-%s-%d@elective", student.RegisterNo, entry.CourseID, entry.Day, start),
+			Summary:     summary,
+			Location:    entry.Room,
+			Description: "Seat " + entry.SeatNo,
+			Start:       date.Add(time.Duration(start) * time.Minute),
+			End:         date.Add(time.Duration(end) * time.Minute),
+			Weekly:      true,
+			Until:       until,
 		})
 	}
+	return calendar
+}
+
+// firstWeekday returns midnight of the first day on or after from that falls
+// on models.Weekdays[dayIndex].
+func firstWeekday(from time.Time, dayIndex int) time.Time {
+	date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	// Weekdays starts on Monday, time.Weekday on Sunday
+	weekday := time.Weekday((dayIndex + 1) % 7)
+	return date.AddDate(0, 0, (int(weekday)-int(date.Weekday())+7)%7)
+}
+
+func (h *CourseHandler) GetCourse(c *fiber.Ctx) error {
+	student := c.Locals("student").(*models.Student)
+
+	courseID, err := c.ParamsInt("id")
+	if err != nil || courseID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid course id")
+	}
+
+	detail, err := h.courseService.GetCourseDetail(student, uint(courseID))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"course": CourseDetailResponse{
+			CourseResponse:   toCourseResponse(detail.Course),
+			SeatMap:          detail.Course.SeatMap(),
+			BookingsByDept:   detail.BookingsByDept,
+			WaitlistLength:   detail.WaitlistLength,
+			Booked:           detail.Booked,
+			Eligible:         detail.Eligible,
+			IneligibleReason: detail.IneligibleReason,
+			Quotas:           toQuotaUsageResponse(detail.Quotas),
+			QuotaReleased:    detail.QuotaReleased,
+		},
+	})
+}
+
+func (h *CourseHandler) CreateCourse(c *fiber.Ctx) error {
+	var req CreateCourseRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
 
 	course := &models.Course{
 		Name:        req.Name,
@@ -131,48 +457,156 @@ func (h *CourseHandler) CreateCourse(c *fiber.Ctx) error {
 		Departments: models.StringArray(req.Departments),
 		Genres:      models.StringArray(req.Genres),
 		CourseType:  req.CourseType,
+		TermID:      req.TermID,
 		TotalSeats:  req.TotalSeats,
+
+		DepartmentQuotas: models.QuotaMap(req.DepartmentQuotas),
+		QuotaReleaseAt:   req.QuotaReleaseAt,
+
+		Prerequisites:   models.IntArray(req.Prerequisites),
+		ExcludedCourses: models.IntArray(req.ExcludedCourses),
+		MinSemester:     req.MinSemester,
+		MinCGPA:         req.MinCGPA,
+
+		Sessions: toSessionList(req.Sessions),
 	}
 
 	err := h.courseService.CreateCourse(course)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Course created successfully",
 		"course": fiber.Map{
-			"id":          course.ID,
-			"name":        course.Name,
-			"course_type": course.CourseType,
-			"departments": course.Departments,
-			"rating":      course.Rating,
-			"total_seats": course.TotalSeats,
+			"id":                course.ID,
+			"name":              course.Name,
+			"course_type":       course.CourseType,
+			"term_id":           course.TermID,
+			"departments":       course.Departments,
+			"rating":            course.Rating,
+			"total_seats":       course.TotalSeats,
+			"department_quotas": course.DepartmentQuotas,
+			"prerequisites":     course.Prerequisites,
+			"excluded_courses":  course.ExcludedCourses,
+			"min_semester":      course.MinSemester,
+			"min_cgpa":          course.MinCGPA,
+			"sessions":          course.Sessions,
 		},
 	})
 
 }
 
+func (h *CourseHandler) UpdateCourse(c *fiber.Ctx) error {
+	courseID, err := c.ParamsInt("id")
+	if err != nil || courseID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid course id")
+	}
+
+	var req UpdateCourseRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	course, err := h.courseService.UpdateCourse(uint(courseID), &models.CourseUpdate{
+		Name:        req.Name,
+		PDFLink:     req.PDFLink,
+		Rating:      req.Rating,
+		StaffNames:  stringArrayOrNil(req.StaffNames),
+		ImageLink:   req.ImageLink,
+		Description: req.Description,
+		Departments: stringArrayOrNil(req.Departments),
+		Genres:      stringArrayOrNil(req.Genres),
+		CourseType:  req.CourseType,
+		TermID:      req.TermID,
+		TotalSeats:  req.TotalSeats,
+
+		DepartmentQuotas: quotaMapOrNil(req.DepartmentQuotas),
+		QuotaReleaseAt:   req.QuotaReleaseAt,
+
+		Prerequisites:   intArrayOrNil(req.Prerequisites),
+		ExcludedCourses: intArrayOrNil(req.ExcludedCourses),
+		MinSemester:     req.MinSemester,
+		MinCGPA:         req.MinCGPA,
+
+		Sessions: sessionListOrNil(req.Sessions),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Course updated successfully",
+		"course":  toCourseResponse(*course),
+	})
+}
+
+func (h *CourseHandler) DeleteCourse(c *fiber.Ctx) error {
+	courseID, err := c.ParamsInt("id")
+	if err != nil || courseID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid course id")
+	}
+
+	if err := h.courseService.DeleteCourse(uint(courseID)); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Course deleted successfully",
+	})
+}
+
+// stringArrayOrNil converts an optional list, turning an explicit null into an
+// empty list so the column never holds null.
+func stringArrayOrNil(values *[]string) *models.StringArray {
+	if values == nil {
+		return nil
+	}
+	array := models.StringArray(*values)
+	if array == nil {
+		array = models.StringArray{}
+	}
+	return &array
+}
+
 func (h *CourseHandler) BookCourse(c *fiber.Ctx) error {
 	student := c.Locals("student").(*models.Student)
 
 	var req BookCourseRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
 	}
 
-	err := h.courseService.BookCourse(student.ID, req.CourseID, req.SeatNo)
+	booking, err := h.courseService.BookCourse(student.ID, req.CourseID, req.SeatNo, req.Section)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Course booked successfully",
+		"message":   "Course booked successfully",
+		"course_id": req.CourseID,
+		"seat_no":   booking.SeatNo,
+		"section":   booking.Section,
+	})
+}
+
+func (h *CourseHandler) SwapCourse(c *fiber.Ctx) error {
+	student := c.Locals("student").(*models.Student)
+
+	var req SwapCourseRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	booking, err := h.courseService.SwapCourse(student.ID, req.FromBookingID, req.CourseID, req.SeatNo, req.Section)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message":   "Course swapped successfully",
+		"course_id": req.CourseID,
+		"seat_no":   booking.SeatNo,
+		"section":   booking.Section,
 	})
 }
 
@@ -181,12 +615,164 @@ func (h *CourseHandler) GetMyBookings(c *fiber.Ctx) error {
 
 	bookings, err := h.courseService.GetStudentBookings(student.ID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
 		"bookings": bookings,
 	})
 }
+
+func (h *CourseHandler) CancelBooking(c *fiber.Ctx) error {
+	student := c.Locals("student").(*models.Student)
+
+	bookingID, err := c.ParamsInt("id")
+	if err != nil || bookingID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid booking id")
+	}
+
+	err = h.courseService.CancelBooking(student.ID, uint(bookingID))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Booking cancelled successfully",
+	})
+}
+
+func (h *CourseHandler) AdminCancelBooking(c *fiber.Ctx) error {
+	admin := c.Locals("student").(*models.Student)
+
+	bookingID, err := c.ParamsInt("id")
+	if err != nil || bookingID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid booking id")
+	}
+
+	err = h.courseService.AdminCancelBooking(admin.ID, uint(bookingID))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Booking cancelled successfully",
+	})
+}
+
+func (h *CourseHandler) JoinWaitlist(c *fiber.Ctx) error {
+	student := c.Locals("student").(*models.Student)
+
+	courseID, err := c.ParamsInt("id")
+	if err != nil || courseID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid course id")
+	}
+
+	position, err := h.courseService.JoinWaitlist(student.ID, uint(courseID))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":  "Joined waitlist successfully",
+		"position": position,
+	})
+}
+
+func (h *CourseHandler) LeaveWaitlist(c *fiber.Ctx) error {
+	student := c.Locals("student").(*models.Student)
+
+	courseID, err := c.ParamsInt("id")
+	if err != nil || courseID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid course id")
+	}
+
+	err = h.courseService.LeaveWaitlist(student.ID, uint(courseID))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Left waitlist successfully",
+	})
+}
+
+func (h *CourseHandler) GetWaitlistPosition(c *fiber.Ctx) error {
+	student := c.Locals("student").(*models.Student)
+
+	courseID, err := c.ParamsInt("id")
+	if err != nil || courseID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid course id")
+	}
+
+	position, length, err := h.courseService.GetWaitlistPosition(student.ID, uint(courseID))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"course_id": courseID,
+		"position":  position,
+		"length":    length,
+	})
+}
+
+func toSessionList(sessions []SessionRequest) models.SessionList {
+	list := make(models.SessionList, 0, len(sessions))
+	for _, session := range sessions {
+		list = append(list, models.Session{
+			Section: session.Section,
+			Day:     session.Day,
+			Start:   session.Start,
+			End:     session.End,
+			Room:    session.Room,
+		})
+	}
+	return list
+}
+
+func sessionListOrNil(sessions *[]SessionRequest) *models.SessionList {
+	if sessions == nil {
+		return nil
+	}
+	list := toSessionList(*sessions)
+	return &list
+}
+
+func intArrayOrNil(values *[]int) *models.IntArray {
+	if values == nil {
+		return nil
+	}
+	array := models.IntArray(*values)
+	if array == nil {
+		array = models.IntArray{}
+	}
+	return &array
+}
+
+func quotaMapOrNil(values *map[string]int) *models.QuotaMap {
+	if values == nil {
+		return nil
+	}
+	quotas := models.QuotaMap(*values)
+	if quotas == nil {
+		quotas = models.QuotaMap{}
+	}
+	return &quotas
+}
+
+func toQuotaUsageResponse(usage []models.DepartmentQuotaUsage) []QuotaUsageResponse {
+	response := make([]QuotaUsageResponse, 0, len(usage))
+	for _, quota := range usage {
+		remaining := int64(quota.Quota) - quota.Booked
+		if remaining < 0 {
+			remaining = 0
+		}
+		response = append(response, QuotaUsageResponse{
+			Department: quota.Department,
+			Quota:      quota.Quota,
+			Booked:     quota.Booked,
+			Remaining:  remaining,
+		})
+	}
+	return response
+}
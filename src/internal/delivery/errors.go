@@ -0,0 +1,79 @@
+package delivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sk/elective/src/internal/domain"
+	"gorm.io/gorm"
+)
+
+// ErrorResponse is the body of every error answer:
+//
+//	{"error": "course is full", "code": "course_full"}
+//
+// Code is stable and meant for programs; Error is meant for people. Fields
+// is only set for validation errors.
+type ErrorResponse struct {
+	Error  string              `json:"error"`
+	Code   string              `json:"code"`
+	Fields map[string][]string `json:"fields,omitempty"`
+}
+
+var kindStatus = map[domain.ErrorKind]int{
+	domain.KindBadRequest:    fiber.StatusBadRequest,
+	domain.KindNotFound:      fiber.StatusNotFound,
+	domain.KindConflict:      fiber.StatusConflict,
+	domain.KindSeatTaken:     fiber.StatusConflict,
+	domain.KindQuotaExceeded: fiber.StatusConflict,
+	domain.KindUnauthorized:  fiber.StatusUnauthorized,
+	domain.KindForbidden:     fiber.StatusForbidden,
+	domain.KindValidation:    fiber.StatusUnprocessableEntity,
+	domain.KindLocked:        fiber.StatusLocked,
+	domain.KindRateLimited:   fiber.StatusTooManyRequests,
+}
+
+// ErrorHandler is the application's fiber error handler. Domain errors are
+// mapped to their status and code; anything unexpected is logged and answered
+// with a generic 500 so that internal details never reach clients.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	status, body := toErrorResponse(err)
+	if status == fiber.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Method(), c.OriginalURL(), err)
+	}
+	return c.Status(status).JSON(body)
+}
+
+func toErrorResponse(err error) (int, ErrorResponse) {
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		return fiber.StatusUnprocessableEntity, ErrorResponse{
+			Error:  "Validation failed",
+			Code:   "validation_failed",
+			Fields: verr.Fields,
+		}
+	}
+
+	var derr *domain.Error
+	if errors.As(err, &derr) {
+		if status, ok := kindStatus[derr.Kind]; ok {
+			return status, ErrorResponse{Error: derr.Message, Code: derr.Code}
+		}
+	}
+
+	// Errors raised by fiber itself, such as unknown routes
+	var ferr *fiber.Error
+	if errors.As(err, &ferr) {
+		code := strings.ToLower(strings.ReplaceAll(http.StatusText(ferr.Code), " ", "_"))
+		return ferr.Code, ErrorResponse{Error: ferr.Message, Code: code}
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fiber.StatusNotFound, ErrorResponse{Error: domain.ErrNotFound.Message, Code: domain.ErrNotFound.Code}
+	}
+
+	return fiber.StatusInternalServerError, ErrorResponse{Error: domain.ErrInternal.Message, Code: domain.ErrInternal.Code}
+}
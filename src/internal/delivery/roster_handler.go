@@ -0,0 +1,52 @@
+package delivery
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sk/elective/src/internal/domain"
+)
+
+type RosterHandler struct {
+	rosterService domain.RosterService
+}
+
+func NewRosterHandler(rosterService domain.RosterService) *RosterHandler {
+	return &RosterHandler{rosterService: rosterService}
+}
+
+// ImportRoster accepts the CSV either as a multipart upload in the "file"
+// field or as the raw request body.
+func (h *RosterHandler) ImportRoster(c *fiber.Ctx) error {
+	var r io.Reader = bytes.NewReader(c.Body())
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return domain.ErrInvalidBody.WithMessage("Could not read uploaded file")
+		}
+		defer file.Close()
+		r = file
+	}
+
+	imported, err := h.rosterService.ImportCSV(r)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Roster imported successfully",
+		"imported": imported,
+	})
+}
+
+func (h *RosterHandler) GetRoster(c *fiber.Ctx) error {
+	entries, err := h.rosterService.GetRoster()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"roster": entries,
+	})
+}
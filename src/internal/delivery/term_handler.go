@@ -0,0 +1,248 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+type TermHandler struct {
+	termService domain.TermService
+	validator   *Validator
+}
+
+func NewTermHandler(termService domain.TermService, validator *Validator) *TermHandler {
+	return &TermHandler{termService: termService, validator: validator}
+}
+
+type CreateTermRequest struct {
+	Name     string    `json:"name" validate:"required"`
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required"`
+	Active   bool      `json:"active"`
+
+	AllocationMode string     `json:"allocation_mode" validate:"omitempty,oneof=first-come preference"`
+	DropDeadline   *time.Time `json:"drop_deadline"`
+}
+
+// UpdateTermRequest is a partial update; omitted fields keep their value.
+type UpdateTermRequest struct {
+	Name     *string    `json:"name" validate:"omitempty,min=1"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	Active   *bool      `json:"active"`
+
+	AllocationMode *string `json:"allocation_mode" validate:"omitempty,oneof=first-come preference"`
+	// DropDeadline is cleared by an explicit null.
+	DropDeadline models.NullableTime `json:"drop_deadline"`
+}
+
+type CreateWindowRequest struct {
+	Department string    `json:"department" validate:"omitempty,department"`
+	Batch      int       `json:"batch" validate:"omitempty,min=1900,max=2999"`
+	OpensAt    time.Time `json:"opens_at" validate:"required"`
+	ClosesAt   time.Time `json:"closes_at" validate:"required"`
+}
+
+type CreatePhaseRequest struct {
+	Name     string    `json:"name" validate:"required"`
+	Batch    int       `json:"batch" validate:"omitempty,min=1900,max=2999"`
+	MinCGPA  float64   `json:"min_cgpa" validate:"min=0,max=10"`
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+}
+
+func (h *TermHandler) GetTerms(c *fiber.Ctx) error {
+	terms, err := h.termService.GetTerms()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"terms": terms,
+	})
+}
+
+func (h *TermHandler) CreateTerm(c *fiber.Ctx) error {
+	var req CreateTermRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	term := &models.AcademicTerm{
+		Name:     req.Name,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Active:   req.Active,
+
+		AllocationMode: req.AllocationMode,
+		DropDeadline:   req.DropDeadline,
+	}
+	if err := h.termService.CreateTerm(term); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Term created successfully",
+		"term":    term,
+	})
+}
+
+func (h *TermHandler) UpdateTerm(c *fiber.Ctx) error {
+	termID, err := c.ParamsInt("id")
+	if err != nil || termID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid term id")
+	}
+
+	var req UpdateTermRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	term, err := h.termService.UpdateTerm(uint(termID), &models.AcademicTermUpdate{
+		Name:     req.Name,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Active:   req.Active,
+
+		AllocationMode: req.AllocationMode,
+		DropDeadline:   req.DropDeadline,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Term updated successfully",
+		"term":    term,
+	})
+}
+
+func (h *TermHandler) GetWindows(c *fiber.Ctx) error {
+	termID, err := c.ParamsInt("id")
+	if err != nil || termID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid term id")
+	}
+
+	windows, err := h.termService.GetWindows(uint(termID))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"windows": windows,
+	})
+}
+
+func (h *TermHandler) CreateWindow(c *fiber.Ctx) error {
+	termID, err := c.ParamsInt("id")
+	if err != nil || termID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid term id")
+	}
+
+	var req CreateWindowRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	window := &models.BookingWindow{
+		TermID:     uint(termID),
+		Department: req.Department,
+		Batch:      req.Batch,
+		OpensAt:    req.OpensAt,
+		ClosesAt:   req.ClosesAt,
+	}
+	if err := h.termService.CreateWindow(window); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Booking window created successfully",
+		"window":  window,
+	})
+}
+
+func (h *TermHandler) DeleteWindow(c *fiber.Ctx) error {
+	termID, err := c.ParamsInt("id")
+	if err != nil || termID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid term id")
+	}
+
+	windowID, err := c.ParamsInt("window_id")
+	if err != nil || windowID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid booking window id")
+	}
+
+	if err := h.termService.DeleteWindow(uint(termID), uint(windowID)); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Booking window deleted successfully",
+	})
+}
+
+func (h *TermHandler) GetPhases(c *fiber.Ctx) error {
+	termID, err := c.ParamsInt("id")
+	if err != nil || termID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid term id")
+	}
+
+	phases, err := h.termService.GetPhases(uint(termID))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"phases": phases,
+	})
+}
+
+func (h *TermHandler) CreatePhase(c *fiber.Ctx) error {
+	termID, err := c.ParamsInt("id")
+	if err != nil || termID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid term id")
+	}
+
+	var req CreatePhaseRequest
+	if err := h.validator.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	phase := &models.BookingPhase{
+		TermID:   uint(termID),
+		Name:     req.Name,
+		Batch:    req.Batch,
+		MinCGPA:  req.MinCGPA,
+		StartsAt: req.StartsAt,
+	}
+	if err := h.termService.CreatePhase(phase); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Booking phase created successfully",
+		"phase":   phase,
+	})
+}
+
+func (h *TermHandler) DeletePhase(c *fiber.Ctx) error {
+	termID, err := c.ParamsInt("id")
+	if err != nil || termID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid term id")
+	}
+
+	phaseID, err := c.ParamsInt("phase_id")
+	if err != nil || phaseID < 1 {
+		return domain.ErrInvalidID.WithMessage("Invalid booking phase id")
+	}
+
+	if err := h.termService.DeletePhase(uint(termID), uint(phaseID)); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Booking phase deleted successfully",
+	})
+}
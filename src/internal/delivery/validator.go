@@ -0,0 +1,121 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sk/elective/src/internal/domain"
+)
+
+// Validator parses request bodies and enforces their validate struct tags.
+// Besides the built-in rules it understands "department", which accepts only
+// the configured department codes.
+type Validator struct {
+	validate    *validator.Validate
+	departments map[string]struct{}
+}
+
+func NewValidator(departments []string) *Validator {
+	v := &Validator{
+		validate:    validator.New(validator.WithRequiredStructEnabled()),
+		departments: make(map[string]struct{}, len(departments)),
+	}
+	for _, department := range departments {
+		v.departments[department] = struct{}{}
+	}
+
+	// Report fields by their JSON name
+	v.validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.validate.RegisterValidation("department", func(fl validator.FieldLevel) bool {
+		_, ok := v.departments[fl.Field().String()]
+		return ok
+	})
+
+	return v
+}
+
+// ParseBody decodes the request body into out and validates it. It returns
+// domain.ErrInvalidBody when the body cannot be decoded and a
+// *domain.ValidationError listing every failing field otherwise.
+func (v *Validator) ParseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		return domain.ErrInvalidBody
+	}
+	return v.Struct(out)
+}
+
+// ParseQuery is ParseBody for the query string.
+func (v *Validator) ParseQuery(c *fiber.Ctx, out interface{}) error {
+	if err := c.QueryParser(out); err != nil {
+		return domain.ErrInvalidBody.WithMessage("Invalid query parameters")
+	}
+	return v.Struct(out)
+}
+
+func (v *Validator) Struct(s interface{}) error {
+	err := v.validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	verr := domain.NewValidationError()
+	for _, fe := range fieldErrs {
+		verr.Add(fieldPath(fe), fieldMessage(fe))
+	}
+	return verr
+}
+
+// fieldPath drops the struct name from the namespace, e.g.
+// "CreateCourseRequest.departments[0]" becomes "departments[0]".
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fe.Kind() == reflect.String || fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at least %s characters or items", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String || fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at most %s characters or items", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "url":
+		return "must be a valid URL"
+	case "numeric":
+		return "must be a number"
+	case "department":
+		return "must be a known department code"
+	case "datetime":
+		return fmt.Sprintf("must match the format %s", fe.Param())
+	default:
+		return fmt.Sprintf("failed the %q rule", fe.Tag())
+	}
+}
@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// ErrorKind classifies a domain error. The delivery layer maps each kind to an
+// HTTP status.
+type ErrorKind string
+
+const (
+	KindBadRequest    ErrorKind = "bad_request"
+	KindNotFound      ErrorKind = "not_found"
+	KindConflict      ErrorKind = "conflict"
+	KindSeatTaken     ErrorKind = "seat_taken"
+	KindQuotaExceeded ErrorKind = "quota_exceeded"
+	KindUnauthorized  ErrorKind = "unauthorized"
+	KindForbidden     ErrorKind = "forbidden"
+	KindValidation    ErrorKind = "validation"
+	KindLocked        ErrorKind = "locked"
+	KindRateLimited   ErrorKind = "rate_limited"
+)
+
+// Error is an expected failure whose message is safe to show to clients.
+// Code is a stable, machine-readable identifier for the specific failure.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+}
+
+func NewError(kind ErrorKind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches errors with the same code, so a copy made by WithMessage is
+// still recognised as the original sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithMessage returns a copy of e with a more specific message.
+func (e *Error) WithMessage(message string) *Error {
+	return &Error{Kind: e.Kind, Code: e.Code, Message: message}
+}
+
+var (
+	ErrInvalidBody  = NewError(KindBadRequest, "invalid_body", "Invalid request body")
+	ErrInvalidID    = NewError(KindBadRequest, "invalid_id", "Invalid id")
+	ErrUnauthorized = NewError(KindUnauthorized, "unauthorized", "Invalid or expired token")
+	ErrForbidden    = NewError(KindForbidden, "forbidden", "You do not have permission to perform this action")
+	ErrNotFound     = NewError(KindNotFound, "not_found", "Resource not found")
+	ErrInternal     = NewError("", "internal_error", "Internal server error")
+)
+
+// Auth errors
+var (
+	ErrStudentExists       = NewError(KindConflict, "student_exists", "student already exists")
+	ErrStudentNotFound     = NewError(KindNotFound, "student_not_found", "student not found")
+	ErrNotOnRoster         = NewError(KindForbidden, "not_on_roster", "register number is not on the student roster")
+	ErrInvalidCredentials  = NewError(KindUnauthorized, "invalid_credentials", "invalid credentials")
+	ErrInvalidRole         = NewError(KindBadRequest, "invalid_role", "role must be student, staff or admin")
+	ErrInvalidRefreshToken = NewError(KindUnauthorized, "invalid_refresh_token", "invalid or expired refresh token")
+	ErrRefreshTokenReused  = NewError(KindUnauthorized, "refresh_token_reused", "refresh token has already been used, please log in again")
+	ErrInvalidResetToken   = NewError(KindBadRequest, "invalid_reset_token", "invalid or expired password reset token")
+	ErrWrongPassword       = NewError(KindBadRequest, "wrong_password", "current password is incorrect")
+	ErrAccountLocked       = NewError(KindLocked, "account_locked", "account is temporarily locked after too many failed logins")
+	ErrLoginThrottled      = NewError(KindRateLimited, "login_throttled", "too many failed logins, try again later")
+	ErrResetThrottled      = NewError(KindRateLimited, "reset_throttled", "too many password reset requests, try again later")
+)
+
+// Course and booking errors
+var (
+	ErrCourseNotFound      = NewError(KindNotFound, "course_not_found", "course not found")
+	ErrCourseNotOffered    = NewError(KindForbidden, "course_not_offered", "course is not offered to your department")
+	ErrInvalidSection      = NewError(KindBadRequest, "invalid_section", "no such section")
+	ErrCourseFull          = NewError(KindSeatTaken, "course_full", "course is full")
+	ErrSeatTaken           = NewError(KindSeatTaken, "seat_taken", "seat already booked")
+	ErrDepartmentQuotaFull = NewError(KindSeatTaken, "department_quota_full", "the seats open to your department are all booked")
+	ErrInvalidSeat         = NewError(KindBadRequest, "invalid_seat", "invalid seat number")
+	ErrTypeQuotaExceeded   = NewError(KindQuotaExceeded, "course_type_quota_exceeded", "you have already booked the maximum number of courses in this category")
+	ErrBookingNotFound     = NewError(KindNotFound, "booking_not_found", "booking not found")
+	ErrDropDeadlinePassed  = NewError(KindForbidden, "drop_deadline_passed", "the drop deadline has passed")
+	ErrNothingToSwap       = NewError(KindNotFound, "no_booking_to_swap", "you have no booking in this course's category to swap")
+	ErrAmbiguousSwap       = NewError(KindBadRequest, "ambiguous_swap", "you have several bookings in this category, choose one with from_booking_id")
+	ErrAlreadyBooked       = NewError(KindConflict, "already_booked", "you have already booked this course")
+	ErrCourseNotFull       = NewError(KindConflict, "course_not_full", "course still has free seats, book it directly")
+	ErrAlreadyWaitlisted   = NewError(KindConflict, "already_waitlisted", "you are already on the waitlist for this course")
+	ErrNotWaitlisted       = NewError(KindNotFound, "not_waitlisted", "you are not on the waitlist for this course")
+	ErrSeatsBelowBookings  = NewError(KindConflict, "seats_below_bookings", "total seats cannot be reduced below the seats already booked")
+	ErrCourseHasBookings   = NewError(KindConflict, "course_has_bookings", "course type cannot be changed once the course has bookings")
+	ErrSemesterIneligible  = NewError(KindForbidden, "semester_ineligible", "your semester is not eligible for this category")
+	ErrTimetableClash      = NewError(KindConflict, "timetable_clash", "this course meets at the same time as a course you have booked")
+)
+
+// Course eligibility rule errors
+var (
+	ErrSemesterTooLow       = NewError(KindForbidden, "semester_too_low", "your semester is below the minimum for this course")
+	ErrCGPATooLow           = NewError(KindForbidden, "cgpa_too_low", "your CGPA is below the minimum for this course")
+	ErrPrerequisiteMissing  = NewError(KindForbidden, "prerequisite_missing", "you have not completed the prerequisites of this course")
+	ErrExcludedCourseBooked = NewError(KindForbidden, "excluded_course_booked", "you have booked a course that excludes this one")
+)
+
+// Term and booking window errors
+var (
+	ErrTermNotFound          = NewError(KindNotFound, "term_not_found", "academic term not found")
+	ErrTermExists            = NewError(KindConflict, "term_exists", "an academic term with this name already exists")
+	ErrWindowNotFound        = NewError(KindNotFound, "booking_window_not_found", "booking window not found")
+	ErrPhaseNotFound         = NewError(KindNotFound, "booking_phase_not_found", "booking phase not found")
+	ErrBookingWindowNotOpen  = NewError(KindForbidden, "booking_window_not_open", "booking is not open yet")
+	ErrBookingWindowClosed   = NewError(KindForbidden, "booking_window_closed", "booking has closed")
+	ErrNoActiveTerm          = NewError(KindNotFound, "no_active_term", "no academic term is open for booking")
+	ErrInvalidAllocationMode = NewError(KindBadRequest, "invalid_allocation_mode", "allocation mode must be first-come or preference")
+)
+
+// Preference allocation errors
+var (
+	ErrPreferenceAllocation = NewError(KindForbidden, "preference_allocation", "seats in this term are allocated by preference, submit your preferences instead")
+	ErrNotPreferenceTerm    = NewError(KindBadRequest, "not_preference_term", "this term does not allocate seats by preference")
+	ErrAllocationDone       = NewError(KindConflict, "allocation_done", "seats for this term have already been allocated")
+	ErrPreferencesOpen      = NewError(KindConflict, "preferences_open", "preferences can still be submitted for this term")
+	ErrInvalidPreference    = NewError(KindBadRequest, "invalid_preference", "invalid course preference")
+)
+
+// Elective category errors
+var (
+	ErrCategoryNotFound = NewError(KindNotFound, "category_not_found", "elective category not found")
+	ErrCategoryExists   = NewError(KindConflict, "category_exists", "an elective category with this name already exists")
+	ErrCategoryInUse    = NewError(KindConflict, "category_in_use", "elective category still has courses")
+)
+
+// ValidationError reports every problem found with the input, keyed by the
+// JSON name of the offending field.
+type ValidationError struct {
+	Fields map[string][]string `json:"fields"`
+}
+
+func NewValidationError() *ValidationError {
+	return &ValidationError{Fields: make(map[string][]string)}
+}
+
+// Add records a problem with field.
+func (e *ValidationError) Add(field, message string) {
+	e.Fields[field] = append(e.Fields[field], message)
+}
+
+// ErrOrNil returns e if any problem was recorded, and nil otherwise.
+func (e *ValidationError) ErrOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field+": "+strings.Join(e.Fields[field], ", "))
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
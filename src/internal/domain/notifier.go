@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+// Notifier delivers out-of-band messages such as password reset links to
+// students.
+type Notifier interface {
+	SendPasswordReset(student *models.Student, token string, expiresAt time.Time) error
+}
@@ -1,24 +1,144 @@
 package domain
 
-import "github.com/sk/elective/src/internal/repository/models"
+import (
+	"time"
+
+	"github.com/sk/elective/src/internal/repository/models"
+)
 
 type StudentRepository interface {
 	Create(student *models.Student) error
+	Update(student *models.Student) error
 	GetByRegisterNo(registerNo string) (*models.Student, error)
 	GetByID(id uint) (*models.Student, error)
+	GetByIDForUpdate(id uint) (*models.Student, error)
+	GetByIDForUpdateSkipLocked(id uint) (*models.Student, error)
+	GetByIDsForUpdate(ids []uint) ([]models.Student, error)
+}
+
+type RosterRepository interface {
+	Upsert(entries []models.RosterEntry, optional []string) error
+	GetByRegisterNo(registerNo string) (*models.RosterEntry, error)
+	GetAll() ([]models.RosterEntry, error)
+	SyncStudents(registerNos []string, optional []string) error
+}
+
+type ElectiveCategoryRepository interface {
+	Create(category *models.ElectiveCategory) error
+	Update(category *models.ElectiveCategory) error
+	Delete(id uint) error
+	GetByID(id uint) (*models.ElectiveCategory, error)
+	GetByName(name string) (*models.ElectiveCategory, error)
+	GetAll() ([]models.ElectiveCategory, error)
+}
+
+type AcademicTermRepository interface {
+	Create(term *models.AcademicTerm) error
+	Update(term *models.AcademicTerm) error
+	GetByID(id uint) (*models.AcademicTerm, error)
+	GetByName(name string) (*models.AcademicTerm, error)
+	GetAll() ([]models.AcademicTerm, error)
+	GetActive() (*models.AcademicTerm, error)
+	Deactivate(exceptID uint) error
+}
+
+type BookingWindowRepository interface {
+	Create(window *models.BookingWindow) error
+	Delete(termID uint, id uint) error
+	GetByTermID(termID uint) ([]models.BookingWindow, error)
+}
+
+type BookingPhaseRepository interface {
+	Create(phase *models.BookingPhase) error
+	Delete(termID uint, id uint) error
+	GetByTermID(termID uint) ([]models.BookingPhase, error)
 }
 
 type CourseRepository interface {
-	GetAll() ([]models.Course, error)
+	Find(query models.CourseQuery) ([]models.Course, int64, error)
+	CountByType(courseType int) (int64, error)
 	GetByID(id uint) (*models.Course, error)
-	GetByDepartmentAndType(department string, courseType int) ([]models.Course, error)
+	GetByIDForUpdate(id uint) (*models.Course, error)
+	GetByIDs(ids []uint) ([]models.Course, error)
+	GetByTermIDForUpdate(termID uint) ([]models.Course, error)
 	Update(course *models.Course) error
-    Create(course *models.Course) error
+	Create(course *models.Course) error
+	Delete(id uint) error
 }
 
 type CourseBookingRepository interface {
 	Create(booking *models.CourseBooking) error
+	GetByID(id uint) (*models.CourseBooking, error)
+	Cancel(booking *models.CourseBooking, cancelledBy uint) error
 	GetByStudentID(studentID uint) ([]models.CourseBooking, error)
-	GetByStudentAndType(studentID uint, courseType int) (*models.CourseBooking, error)
-	CountByStudentAndType(studentID uint, courseType int) (int64, error)
+	GetByStudentIDs(studentIDs []uint) ([]models.CourseBooking, error)
+	GetByStudentAndType(studentID uint, termID uint, courseType int) ([]models.CourseBooking, error)
+	CountByStudentAndType(studentID uint, termID uint, courseType int) (int64, error)
+	CountByStudentAndCourse(studentID uint, courseID uint) (int64, error)
+	CountByDepartment(courseID uint) (map[string]int64, error)
+	GetByTermID(termID uint) ([]models.CourseBooking, error)
+}
+
+type CoursePreferenceRepository interface {
+	CreateBatch(preferences []models.CoursePreference) error
+	DeleteByStudentAndCategory(studentID uint, termID uint, categoryID uint) error
+	GetByStudentAndTerm(studentID uint, termID uint) ([]models.CoursePreference, error)
+	GetByTermID(termID uint) ([]models.CoursePreference, error)
+}
+
+type WaitlistRepository interface {
+	Create(entry *models.Waitlist) error
+	Delete(studentID uint, courseID uint) error
+	GetByStudentAndCourse(studentID uint, courseID uint) (*models.Waitlist, error)
+	GetByCourseID(courseID uint) ([]models.Waitlist, error)
+	DeleteByCourseID(courseID uint) error
+	CountByCourseID(courseID uint) (int64, error)
+	Position(entry *models.Waitlist) (int64, error)
+}
+
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	GetByHash(tokenHash string) (*models.RefreshToken, error)
+	GetByHashForUpdate(tokenHash string) (*models.RefreshToken, error)
+	Revoke(id uint) error
+	RevokeFamily(familyID string) error
+	RevokeByStudentID(studentID uint) error
+}
+
+type PasswordResetTokenRepository interface {
+	Create(token *models.PasswordResetToken) error
+	GetByHashForUpdate(tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(id uint) error
+	InvalidateByStudentID(studentID uint) error
+}
+
+type LoginAttemptRepository interface {
+	GetByKey(key string) (*models.LoginAttempt, error)
+	RecordFailure(key string, window time.Duration) (*models.LoginAttempt, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+// Repositories groups repositories that share the same database session,
+// so that calls made through them take part in the same transaction.
+type Repositories struct {
+	Students    StudentRepository
+	Roster      RosterRepository
+	Categories  ElectiveCategoryRepository
+	Terms       AcademicTermRepository
+	Windows     BookingWindowRepository
+	Phases      BookingPhaseRepository
+	Courses     CourseRepository
+	Bookings    CourseBookingRepository
+	Waitlists   WaitlistRepository
+	Preferences CoursePreferenceRepository
+
+	RefreshTokens       RefreshTokenRepository
+	PasswordResetTokens PasswordResetTokenRepository
+}
+
+// UnitOfWork runs fn inside a single transaction. The transaction is committed
+// when fn returns nil and rolled back when it returns an error or panics.
+type UnitOfWork interface {
+	Do(fn func(repos *Repositories) error) error
 }
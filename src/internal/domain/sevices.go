@@ -1,17 +1,71 @@
 package domain
 
-import "github.com/sk/elective/src/internal/repository/models"
+import (
+    "io"
+
+    "github.com/sk/elective/src/internal/repository/models"
+)
 
 type AuthService interface {
-    Register(registerNo, password, department, name string) (*models.Student, error)
-    Login(registerNo, password string) (string, *models.Student, error)
+    Register(registerNo, password, name string) (*models.Student, error)
+    Login(registerNo, password, ip string) (*models.TokenPair, *models.Student, error)
+    Refresh(refreshToken string) (*models.TokenPair, error)
+    Logout(refreshToken string) error
     ValidateToken(token string) (*models.Student, error)
+    ForgotPassword(registerNo, ip string) error
+    ResetPassword(token, newPassword string) error
+    ChangePassword(studentID uint, currentPassword, newPassword string) error
+    JWKS() []models.JSONWebKey
+    SetRole(registerNo, role string) (*models.Student, error)
+    BootstrapAdmins() error
+    GetLoginLock(registerNo string) (*models.LoginAttempt, error)
+    UnlockAccount(registerNo string) error
+}
+
+type RosterService interface {
+    ImportCSV(r io.Reader) (int, error)
+    GetRoster() ([]models.RosterEntry, error)
+}
+
+type ElectiveCategoryService interface {
+    GetCategories() ([]models.ElectiveCategory, error)
+    CreateCategory(category *models.ElectiveCategory) error
+    UpdateCategory(categoryID uint, update *models.ElectiveCategoryUpdate) (*models.ElectiveCategory, error)
+    DeleteCategory(categoryID uint) error
+}
+
+type TermService interface {
+    GetTerms() ([]models.AcademicTerm, error)
+    CreateTerm(term *models.AcademicTerm) error
+    UpdateTerm(termID uint, update *models.AcademicTermUpdate) (*models.AcademicTerm, error)
+    GetWindows(termID uint) ([]models.BookingWindow, error)
+    CreateWindow(window *models.BookingWindow) error
+    DeleteWindow(termID uint, windowID uint) error
+    GetPhases(termID uint) ([]models.BookingPhase, error)
+    CreatePhase(phase *models.BookingPhase) error
+    DeletePhase(termID uint, phaseID uint) error
+}
+
+type AllocationService interface {
+    SubmitPreferences(student *models.Student, termID uint, categoryID uint, courseIDs []uint) ([]models.CoursePreference, error)
+    GetPreferences(studentID uint, termID uint) ([]models.CoursePreference, error)
+    RunAllocation(termID uint, seed int64, commit bool) (*models.AllocationReport, error)
 }
 
 type CourseService interface {
-    GetAvailableCourses(studentID uint, department string) ([]models.Course, error)
-    BookCourse(studentID uint, courseID uint, seatNo string) error
+    GetAvailableCourses(student *models.Student, query models.CourseQuery) (*models.CoursePage, error)
+    GetCourseDetail(student *models.Student, courseID uint) (*models.CourseDetail, error)
+    BookCourse(studentID uint, courseID uint, seatNo string, section string) (*models.CourseBooking, error)
+    SwapCourse(studentID uint, fromBookingID uint, courseID uint, seatNo string, section string) (*models.CourseBooking, error)
+    CancelBooking(studentID uint, bookingID uint) error
+    AdminCancelBooking(adminID uint, bookingID uint) error
+    JoinWaitlist(studentID uint, courseID uint) (int64, error)
+    LeaveWaitlist(studentID uint, courseID uint) error
+    GetWaitlistPosition(studentID uint, courseID uint) (int64, int64, error)
     GetStudentBookings(studentID uint) ([]models.CourseBooking, error)
-    CreateCourse(course *models.Course) error 
-    GetAllCourses()  ([]models.Course, error)
+    GetTimetable(studentID uint, termID uint) (*models.Timetable, error)
+    CreateCourse(course *models.Course) error
+    UpdateCourse(courseID uint, update *models.CourseUpdate) (*models.Course, error)
+    DeleteCourse(courseID uint) error
+    GetAllCourses(query models.CourseQuery) (*models.CoursePage, error)
 }
\ No newline at end of file
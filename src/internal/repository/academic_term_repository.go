@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type academicTermRepository struct {
+	db *gorm.DB
+}
+
+func NewAcademicTermRepository(db *gorm.DB) domain.AcademicTermRepository {
+	return &academicTermRepository{db: db}
+}
+
+func (r *academicTermRepository) Create(term *models.AcademicTerm) error {
+	return r.db.Create(term).Error
+}
+
+func (r *academicTermRepository) Update(term *models.AcademicTerm) error {
+	return r.db.Save(term).Error
+}
+
+func (r *academicTermRepository) GetByID(id uint) (*models.AcademicTerm, error) {
+	var term models.AcademicTerm
+	err := r.db.First(&term, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &term, nil
+}
+
+func (r *academicTermRepository) GetByName(name string) (*models.AcademicTerm, error) {
+	var term models.AcademicTerm
+	err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&term).Error
+	if err != nil {
+		return nil, err
+	}
+	return &term, nil
+}
+
+func (r *academicTermRepository) GetAll() ([]models.AcademicTerm, error) {
+	var terms []models.AcademicTerm
+	err := r.db.Order("starts_at DESC").Find(&terms).Error
+	return terms, err
+}
+
+func (r *academicTermRepository) GetActive() (*models.AcademicTerm, error) {
+	var term models.AcademicTerm
+	err := r.db.Where("active = ?", true).First(&term).Error
+	if err != nil {
+		return nil, err
+	}
+	return &term, nil
+}
+
+// Deactivate clears the active flag on every term except exceptID.
+func (r *academicTermRepository) Deactivate(exceptID uint) error {
+	return r.db.Model(&models.AcademicTerm{}).
+		Where("active = ? AND id <> ?", true, exceptID).
+		Update("active", false).Error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type bookingPhaseRepository struct {
+	db *gorm.DB
+}
+
+func NewBookingPhaseRepository(db *gorm.DB) domain.BookingPhaseRepository {
+	return &bookingPhaseRepository{db: db}
+}
+
+func (r *bookingPhaseRepository) Create(phase *models.BookingPhase) error {
+	return r.db.Create(phase).Error
+}
+
+func (r *bookingPhaseRepository) Delete(termID uint, id uint) error {
+	result := r.db.Where("term_id = ?", termID).Delete(&models.BookingPhase{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *bookingPhaseRepository) GetByTermID(termID uint) ([]models.BookingPhase, error) {
+	var phases []models.BookingPhase
+	err := r.db.Where("term_id = ?", termID).Order("starts_at").Find(&phases).Error
+	return phases, err
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type bookingWindowRepository struct {
+	db *gorm.DB
+}
+
+func NewBookingWindowRepository(db *gorm.DB) domain.BookingWindowRepository {
+	return &bookingWindowRepository{db: db}
+}
+
+func (r *bookingWindowRepository) Create(window *models.BookingWindow) error {
+	return r.db.Create(window).Error
+}
+
+func (r *bookingWindowRepository) Delete(termID uint, id uint) error {
+	result := r.db.Where("term_id = ?", termID).Delete(&models.BookingWindow{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *bookingWindowRepository) GetByTermID(termID uint) ([]models.BookingWindow, error) {
+	var windows []models.BookingWindow
+	err := r.db.Where("term_id = ?", termID).Order("opens_at").Find(&windows).Error
+	return windows, err
+}
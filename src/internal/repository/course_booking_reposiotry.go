@@ -18,28 +18,101 @@ func (r *courseBookingRepository) Create(booking *models.CourseBooking) error {
     return r.db.Create(booking).Error
 }
 
+func (r *courseBookingRepository) GetByID(id uint) (*models.CourseBooking, error) {
+    var booking models.CourseBooking
+    err := r.db.First(&booking, id).Error
+    if err != nil {
+        return nil, err
+    }
+    return &booking, nil
+}
+
+// Cancel soft deletes the booking and records who cancelled it. It returns
+// gorm.ErrRecordNotFound if the booking was already cancelled.
+func (r *courseBookingRepository) Cancel(booking *models.CourseBooking, cancelledBy uint) error {
+    result := r.db.Model(booking).Update("cancelled_by", cancelledBy)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
+    }
+    return r.db.Delete(booking).Error
+}
+
 func (r *courseBookingRepository) GetByStudentID(studentID uint) ([]models.CourseBooking, error) {
     var bookings []models.CourseBooking
-    err := r.db.Preload("Course").Where("student_id = ?", studentID).Find(&bookings).Error
+    // Deleted courses are still loaded so the booking history stays complete
+    err := r.db.Preload("Course", func(db *gorm.DB) *gorm.DB {
+        return db.Unscoped()
+    }).Where("student_id = ?", studentID).Find(&bookings).Error
     return bookings, err
 }
 
-func (r *courseBookingRepository) GetByStudentAndType(studentID uint, courseType int) (*models.CourseBooking, error) {
-    var booking models.CourseBooking
+// GetByStudentIDs is GetByStudentID for several students at once.
+func (r *courseBookingRepository) GetByStudentIDs(studentIDs []uint) ([]models.CourseBooking, error) {
+    var bookings []models.CourseBooking
+    err := r.db.Preload("Course", func(db *gorm.DB) *gorm.DB {
+        return db.Unscoped()
+    }).Where("student_id IN ?", studentIDs).Find(&bookings).Error
+    return bookings, err
+}
+
+// GetByStudentAndType returns the student's bookings in a category for a
+// term, oldest first.
+func (r *courseBookingRepository) GetByStudentAndType(studentID uint, termID uint, courseType int) ([]models.CourseBooking, error) {
+    var bookings []models.CourseBooking
     err := r.db.Joins("JOIN courses ON courses.id = course_bookings.course_id").
-        Where("course_bookings.student_id = ? AND courses.course_type = ?", studentID, courseType).
-        First(&booking).Error
-    if err != nil {
-        return nil, err
-    }
-    return &booking, nil
+        Where("course_bookings.student_id = ? AND course_bookings.term_id = ? AND courses.course_type = ? AND courses.deleted_at IS NULL", studentID, termID, courseType).
+        Order("course_bookings.created_at").
+        Find(&bookings).Error
+    return bookings, err
 }
 
-func (r *courseBookingRepository) CountByStudentAndType(studentID uint, courseType int) (int64, error) {
+func (r *courseBookingRepository) CountByStudentAndType(studentID uint, termID uint, courseType int) (int64, error) {
     var count int64
-    err := r.db.Table("course_bookings").
+    err := r.db.Model(&models.CourseBooking{}).
         Joins("JOIN courses ON courses.id = course_bookings.course_id").
-        Where("course_bookings.student_id = ? AND courses.course_type = ?", studentID, courseType).
+        Where("course_bookings.student_id = ? AND course_bookings.term_id = ? AND courses.course_type = ? AND courses.deleted_at IS NULL", studentID, termID, courseType).
+        Count(&count).Error
+    return count, err
+}
+
+
+func (r *courseBookingRepository) CountByStudentAndCourse(studentID uint, courseID uint) (int64, error) {
+    var count int64
+    err := r.db.Model(&models.CourseBooking{}).
+        Where("student_id = ? AND course_id = ?", studentID, courseID).
         Count(&count).Error
     return count, err
 }
+
+// CountByDepartment counts the course's bookings grouped by the department of
+// the booking student.
+func (r *courseBookingRepository) CountByDepartment(courseID uint) (map[string]int64, error) {
+    var rows []struct {
+        Department string
+        Count      int64
+    }
+    err := r.db.Model(&models.CourseBooking{}).
+        Select("students.department AS department, COUNT(*) AS count").
+        Joins("JOIN students ON students.id = course_bookings.student_id").
+        Where("course_bookings.course_id = ?", courseID).
+        Group("students.department").
+        Scan(&rows).Error
+    if err != nil {
+        return nil, err
+    }
+
+    counts := make(map[string]int64, len(rows))
+    for _, row := range rows {
+        counts[row.Department] = row.Count
+    }
+    return counts, nil
+}
+
+func (r *courseBookingRepository) GetByTermID(termID uint) ([]models.CourseBooking, error) {
+    var bookings []models.CourseBooking
+    err := r.db.Where("term_id = ?", termID).Find(&bookings).Error
+    return bookings, err
+}
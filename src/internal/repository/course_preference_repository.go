@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type coursePreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewCoursePreferenceRepository(db *gorm.DB) domain.CoursePreferenceRepository {
+	return &coursePreferenceRepository{db: db}
+}
+
+func (r *coursePreferenceRepository) CreateBatch(preferences []models.CoursePreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return r.db.Create(&preferences).Error
+}
+
+func (r *coursePreferenceRepository) DeleteByStudentAndCategory(studentID uint, termID uint, categoryID uint) error {
+	return r.db.Where("student_id = ? AND term_id = ? AND category_id = ?", studentID, termID, categoryID).
+		Delete(&models.CoursePreference{}).Error
+}
+
+func (r *coursePreferenceRepository) GetByStudentAndTerm(studentID uint, termID uint) ([]models.CoursePreference, error) {
+	var preferences []models.CoursePreference
+	err := r.db.Where("student_id = ? AND term_id = ?", studentID, termID).
+		Order("category_id, rank").
+		Find(&preferences).Error
+	return preferences, err
+}
+
+// GetByTermID returns every preference of the term grouped by student and
+// category, in rank order.
+func (r *coursePreferenceRepository) GetByTermID(termID uint) ([]models.CoursePreference, error) {
+	var preferences []models.CoursePreference
+	err := r.db.Where("term_id = ?", termID).
+		Order("student_id, category_id, rank").
+		Find(&preferences).Error
+	return preferences, err
+}
@@ -1,9 +1,14 @@
 package repository
 
 import (
+	"encoding/json"
+	"sort"
+	"strings"
+
 	"github.com/sk/elective/src/internal/domain"
 	"github.com/sk/elective/src/internal/repository/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type courseRepository struct {
@@ -14,10 +19,127 @@ func NewCourseRepository(db *gorm.DB) domain.CourseRepository {
 	return &courseRepository{db: db}
 }
 
-func (r *courseRepository) GetAll() ([]models.Course, error) {
+// courseSearchVector is the expression behind the idx_courses_search GIN
+// index. Queries must use it verbatim for the index to apply.
+const courseSearchVector = `to_tsvector('english', coalesce(name, '') || ' ' || coalesce(description, ''))`
+
+const courseSeatsLeft = `(total_seats - jsonb_array_length(seats_booked))`
+
+var courseSortColumns = map[string]string{
+	models.CourseSortRating:    "rating",
+	models.CourseSortName:      "name",
+	models.CourseSortSeatsLeft: courseSeatsLeft,
+}
+
+// Find returns the page of courses matching query along with the total number
+// of matches.
+func (r *courseRepository) Find(query models.CourseQuery) ([]models.Course, int64, error) {
+	db := r.db.Model(&models.Course{})
+
+	if query.Search != "" {
+		db = db.Where(courseSearchVector+" @@ websearch_to_tsquery('english', ?)", query.Search)
+	}
+	if query.Genre != "" {
+		db = db.Where("genres @> ?", jsonArray(query.Genre))
+	}
+	if query.Department != "" {
+		db = db.Where("departments @> ?", jsonArray(query.Department))
+	}
+	if query.CourseType != 0 {
+		db = db.Where("course_type = ?", query.CourseType)
+	}
+	if query.TermID != nil {
+		db = db.Where("term_id = ?", *query.TermID)
+	}
+	if query.CourseTypes != nil {
+		db = db.Where("course_type IN ?", query.CourseTypes)
+	}
+	if query.ExcludeBookedBy != 0 {
+		db = db.Where("NOT EXISTS (SELECT 1 FROM course_bookings WHERE course_bookings.course_id = courses.id AND course_bookings.student_id = ? AND course_bookings.deleted_at IS NULL)", query.ExcludeBookedBy)
+	}
+	if query.Staff != "" {
+		db = db.Where("EXISTS (SELECT 1 FROM jsonb_array_elements_text(staff_names) AS staff WHERE staff ILIKE ?)", "%"+escapeLike(query.Staff)+"%")
+	}
+	if query.Available != nil {
+		if *query.Available {
+			db = db.Where(courseSeatsLeft + " > 0")
+		} else {
+			db = db.Where(courseSeatsLeft + " <= 0")
+		}
+	}
+	if query.EligibleFor != nil {
+		db = whereEligible(db, query.EligibleFor)
+	}
+
+	// Start a new session so the count and the page query share the filters
+	// without leaking into each other
+	db = db.Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Sort on id last so that pages are stable
+	if column, ok := courseSortColumns[query.Sort]; ok {
+		direction := " ASC"
+		if query.Desc {
+			direction = " DESC"
+		}
+		db = db.Order(column + direction)
+	}
+	db = db.Order("id ASC")
+
 	var courses []models.Course
-	err := r.db.Find(&courses).Error
-	return courses, err
+	err := db.Offset((query.Page - 1) * query.Limit).Limit(query.Limit).Find(&courses).Error
+	return courses, total, err
+}
+
+// whereEligible keeps the courses whose eligibility rules the student meets.
+// It mirrors the checks made when booking.
+func whereEligible(db *gorm.DB, eligibility *models.Eligibility) *gorm.DB {
+	db = db.Where("min_semester <= ? AND min_cgpa <= ?", eligibility.Semester, eligibility.CGPA)
+
+	completed, _ := json.Marshal(sortedIDs(eligibility.Completed))
+	db = db.Where("coalesce(prerequisites, '[]') <@ ?", string(completed))
+
+	if len(eligibility.Taken) > 0 {
+		db = db.Where("NOT EXISTS (SELECT 1 FROM jsonb_array_elements_text(excluded_courses) AS excluded WHERE excluded::bigint IN ?)", sortedIDs(eligibility.Taken))
+	}
+	if len(eligibility.Excluded) > 0 {
+		excluded := make([]uint, 0, len(eligibility.Excluded))
+		for id := range eligibility.Excluded {
+			excluded = append(excluded, id)
+		}
+		db = db.Where("id NOT IN ?", excluded)
+	}
+	return db
+}
+
+func sortedIDs(set map[uint]bool) []uint {
+	ids := make([]uint, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// jsonArray encodes value as a single element JSON array for jsonb
+// containment checks.
+func jsonArray(value string) string {
+	encoded, _ := json.Marshal([]string{value})
+	return string(encoded)
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (r *courseRepository) CountByType(courseType int) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Course{}).Where("course_type = ?", courseType).Count(&count).Error
+	return count, err
 }
 
 func (r *courseRepository) GetByID(id uint) (*models.Course, error) {
@@ -29,9 +151,28 @@ func (r *courseRepository) GetByID(id uint) (*models.Course, error) {
 	return &course, nil
 }
 
-func (r *courseRepository) GetByDepartmentAndType(department string, courseType int) ([]models.Course, error) {
+// GetByIDForUpdate loads the course and holds a row lock on it until the
+// surrounding transaction ends.
+func (r *courseRepository) GetByIDForUpdate(id uint) (*models.Course, error) {
+	var course models.Course
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&course, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &course, nil
+}
+
+// GetByTermIDForUpdate loads and locks every course of the term in id order.
+func (r *courseRepository) GetByTermIDForUpdate(termID uint) ([]models.Course, error) {
 	var courses []models.Course
-	err := r.db.Where("course_type = ? AND departments @> ?", courseType, `["`+department+`"]`).Find(&courses).Error
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("term_id = ?", termID).Order("id").Find(&courses).Error
+	return courses, err
+}
+
+// GetByIDs loads the given courses, deleted ones included, in id order.
+func (r *courseRepository) GetByIDs(ids []uint) ([]models.Course, error) {
+	var courses []models.Course
+	err := r.db.Unscoped().Where("id IN ?", ids).Order("id").Find(&courses).Error
 	return courses, err
 }
 
@@ -41,4 +182,16 @@ func (r *courseRepository) Update(course *models.Course) error {
 
 func (r *courseRepository) Create(course *models.Course) error {
     return r.db.Create(course).Error
+}
+
+// Delete soft deletes the course. Its bookings are left untouched.
+func (r *courseRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.Course{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
\ No newline at end of file
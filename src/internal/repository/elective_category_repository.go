@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type electiveCategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewElectiveCategoryRepository(db *gorm.DB) domain.ElectiveCategoryRepository {
+	return &electiveCategoryRepository{db: db}
+}
+
+func (r *electiveCategoryRepository) Create(category *models.ElectiveCategory) error {
+	return r.db.Create(category).Error
+}
+
+func (r *electiveCategoryRepository) Update(category *models.ElectiveCategory) error {
+	return r.db.Save(category).Error
+}
+
+func (r *electiveCategoryRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.ElectiveCategory{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *electiveCategoryRepository) GetByID(id uint) (*models.ElectiveCategory, error) {
+	var category models.ElectiveCategory
+	err := r.db.First(&category, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *electiveCategoryRepository) GetByName(name string) (*models.ElectiveCategory, error) {
+	var category models.ElectiveCategory
+	err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *electiveCategoryRepository) GetAll() ([]models.ElectiveCategory, error) {
+	var categories []models.ElectiveCategory
+	err := r.db.Order("id").Find(&categories).Error
+	return categories, err
+}
@@ -4,7 +4,11 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type StringArray []string
@@ -30,43 +34,799 @@ func (a StringArray) Value() (driver.Value, error) {
 	return json.Marshal(a)
 }
 
+type IntArray []int
+
+func (a *IntArray) Scan(value interface{}) error {
+	if value == nil {
+		*a = IntArray{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(bytes, a)
+}
+
+func (a IntArray) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return "[]", nil
+	}
+	return json.Marshal(a)
+}
+
+// NullableTime is an optional timestamp in a partial update. It is left
+// unchanged unless Set, and cleared when Set with a nil Time, which JSON
+// expresses as an explicit null.
+type NullableTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (n *NullableTime) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Time = nil
+		return nil
+	}
+	return json.Unmarshal(data, &n.Time)
+}
+
+// Apply stores the value in field if it is set.
+func (n NullableTime) Apply(field **time.Time) {
+	if n.Set {
+		*field = n.Time
+	}
+}
+
+// QuotaMap maps department codes to seat counts.
+type QuotaMap map[string]int
+
+func (m *QuotaMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = QuotaMap{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(bytes, m)
+}
+
+func (m QuotaMap) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return "{}", nil
+	}
+	return json.Marshal(m)
+}
+
+// Weekdays are the day names a course session may meet on, Monday first.
+var Weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// SessionTimeLayout is the wall clock format of session start and end times.
+const SessionTimeLayout = "15:04"
+
+// Session is one weekly meeting of a course. A course that runs several
+// sections at different hours names the section each session belongs to;
+// sessions without a section are attended by every section.
+type Session struct {
+	Section string `json:"section,omitempty"`
+	Day     string `json:"day"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+	Room    string `json:"room"`
+}
+
+// SharedWith reports whether students of both sections attend the session
+// and other, so that the two must not overlap.
+func (s Session) SharedWith(other Session) bool {
+	return s.Section == "" || other.Section == "" || s.Section == other.Section
+}
+
+// DayIndex returns the position of the session's day in Weekdays, or -1 for
+// an unknown day.
+func (s Session) DayIndex() int {
+	for i, day := range Weekdays {
+		if day == s.Day {
+			return i
+		}
+	}
+	return -1
+}
+
+// Minutes returns the start and end of the session as minutes after
+// midnight. ok is false if either time is malformed.
+func (s Session) Minutes() (start, end int, ok bool) {
+	startAt, err := time.Parse(SessionTimeLayout, s.Start)
+	if err != nil {
+		return 0, 0, false
+	}
+	endAt, err := time.Parse(SessionTimeLayout, s.End)
+	if err != nil {
+		return 0, 0, false
+	}
+	return startAt.Hour()*60 + startAt.Minute(), endAt.Hour()*60 + endAt.Minute(), true
+}
+
+// Overlaps reports whether both sessions meet at the same time. Sessions that
+// merely touch, one ending as the other starts, do not overlap.
+func (s Session) Overlaps(other Session) bool {
+	if s.Day != other.Day {
+		return false
+	}
+	start, end, ok := s.Minutes()
+	otherStart, otherEnd, otherOK := other.Minutes()
+	return ok && otherOK && start < otherEnd && otherStart < end
+}
+
+func (s Session) String() string {
+	if s.Section != "" {
+		return fmt.Sprintf("section %s, %s %s-%s", s.Section, s.Day, s.Start, s.End)
+	}
+	return fmt.Sprintf("%s %s-%s", s.Day, s.Start, s.End)
+}
+
+type SessionList []Session
+
+func (l *SessionList) Scan(value interface{}) error {
+	if value == nil {
+		*l = SessionList{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+func (l SessionList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "[]", nil
+	}
+	return json.Marshal(l)
+}
+
+const (
+	RoleStudent = "student"
+	RoleStaff   = "staff"
+	RoleAdmin   = "admin"
+)
+
+// IsValidRole reports whether role is one of the known roles.
+func IsValidRole(role string) bool {
+	return role == RoleStudent || role == RoleStaff || role == RoleAdmin
+}
+
 type Student struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
 	RegisterNo string    `json:"register_no" gorm:"unique;not null"`
 	Password   string    `json:"-" gorm:"not null"`
 	Name       string    `json:"name" gorm:"not null"`
 	Department string    `json:"department" gorm:"default:'CSE'"`
+	Batch      int       `json:"batch"`
+	Semester   int       `json:"semester"`
+	CGPA       float64   `json:"cgpa"`
+	Role       string    `json:"role" gorm:"not null;default:'student'"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 
+	// Access tokens issued before this moment are rejected.
+	PasswordChangedAt *time.Time `json:"-"`
+
 	// Bookings
 	CourseBookings []CourseBooking `json:"course_bookings,omitempty" gorm:"foreignKey:StudentID"`
 }
+
+// RosterEntry is an official record of a student who may register, as
+// imported by admins. Batch is the year of admission and Semester is the
+// current semester, or 0 if unknown. CGPA is on a 10 point scale.
+type RosterEntry struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	RegisterNo string    `json:"register_no" gorm:"not null;uniqueIndex"`
+	Name       string    `json:"name"`
+	Department string    `json:"department" gorm:"not null"`
+	Batch      int       `json:"batch" gorm:"not null"`
+	Semester   int       `json:"semester"`
+	CGPA       float64   `json:"cgpa"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ElectiveCategory groups courses that share a selection limit, such as
+// Professional Elective or Open Elective. A course's CourseType is the ID of
+// its category. An empty Semesters list means every semester is eligible.
+type ElectiveCategory struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Name          string    `json:"name" gorm:"not null;uniqueIndex"`
+	MaxSelections int       `json:"max_selections" gorm:"not null;default:1"`
+	Semesters     IntArray  `json:"semesters" gorm:"type:jsonb"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// AllowsSemester reports whether students in semester may pick courses of the
+// category.
+func (c *ElectiveCategory) AllowsSemester(semester int) bool {
+	if len(c.Semesters) == 0 {
+		return true
+	}
+	for _, s := range c.Semesters {
+		if s == semester {
+			return true
+		}
+	}
+	return false
+}
+
+// ElectiveCategoryUpdate holds the fields of a partial category update. Nil
+// fields are left unchanged.
+type ElectiveCategoryUpdate struct {
+	Name          *string
+	MaxSelections *int
+	Semesters     *IntArray
+}
+
+// Allocation modes of a term
+const (
+	AllocationFirstCome  = "first-come"
+	AllocationPreference = "preference"
+)
+
+// AcademicTerm is a semester in which courses are offered. Courses and
+// bookings carry the ID of their term, with 0 meaning no term. At most one
+// term is Active, the one students are currently booking for.
+//
+// In first-come terms students book seats directly. In preference terms they
+// submit ranked preferences instead and seats are assigned by an allocation
+// run, after which AllocatedAt is set.
+type AcademicTerm struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	Name           string     `json:"name" gorm:"not null;uniqueIndex"`
+	StartsAt       time.Time  `json:"starts_at" gorm:"not null"`
+	EndsAt         time.Time  `json:"ends_at" gorm:"not null"`
+	Active         bool       `json:"active" gorm:"not null;default:false"`
+	AllocationMode string     `json:"allocation_mode" gorm:"not null;default:'first-come'"`
+	AllocatedAt    *time.Time `json:"allocated_at,omitempty"`
+	// DropDeadline is the last moment students may cancel their own bookings
+	// in the term, or nil for no deadline.
+	DropDeadline *time.Time `json:"drop_deadline,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UsesPreferences reports whether seats are allocated from preferences and
+// the allocation has not run yet.
+func (t *AcademicTerm) UsesPreferences() bool {
+	return t.AllocationMode == AllocationPreference && t.AllocatedAt == nil
+}
+
+// AcademicTermUpdate holds the fields of a partial term update. Nil fields
+// are left unchanged.
+type AcademicTermUpdate struct {
+	Name           *string
+	StartsAt       *time.Time
+	EndsAt         *time.Time
+	Active         *bool
+	AllocationMode *string
+	DropDeadline   NullableTime
+}
+
+// CoursePreference is one entry of a student's ranked course choices for a
+// category in a preference term. Rank 1 is the first choice.
+type CoursePreference struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	StudentID  uint      `json:"student_id" gorm:"not null;uniqueIndex:idx_preference_student_course;index:idx_preference_student_term"`
+	TermID     uint      `json:"term_id" gorm:"not null;index:idx_preference_student_term;index"`
+	CategoryID uint      `json:"category_id" gorm:"not null"`
+	CourseID   uint      `json:"course_id" gorm:"not null;uniqueIndex:idx_preference_student_course"`
+	Rank       int       `json:"rank" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AllocationReport describes the outcome of an allocation run. Seed
+// reproduces the lottery that breaks CGPA ties.
+type AllocationReport struct {
+	TermID      uint                   `json:"term_id"`
+	Seed        int64                  `json:"seed"`
+	Committed   bool                   `json:"committed"`
+	Assignments []AllocationAssignment `json:"assignments"`
+	Unfilled    []AllocationShortfall  `json:"unfilled"`
+	Courses     []AllocationCourse     `json:"courses"`
+}
+
+// AllocationAssignment is a seat given to a student. Rank is the position of
+// the course in the student's preferences.
+type AllocationAssignment struct {
+	StudentID  uint   `json:"student_id"`
+	RegisterNo string `json:"register_no"`
+	CategoryID uint   `json:"category_id"`
+	CourseID   uint   `json:"course_id"`
+	Section    string `json:"section,omitempty"`
+	Rank       int    `json:"rank"`
+	SeatNo     string `json:"seat_no,omitempty"`
+}
+
+// AllocationShortfall records a student left with fewer courses in a category
+// than they could take.
+type AllocationShortfall struct {
+	StudentID  uint   `json:"student_id"`
+	RegisterNo string `json:"register_no"`
+	CategoryID uint   `json:"category_id"`
+	Missing    int    `json:"missing"`
+}
+
+type AllocationCourse struct {
+	CourseID   uint   `json:"course_id"`
+	Name       string `json:"name"`
+	TotalSeats int    `json:"total_seats"`
+	Booked     int    `json:"booked"`
+	Allocated  int    `json:"allocated"`
+}
+
+// BookingWindow is the period in which students may book courses of a term.
+// An empty Department or a zero Batch matches every student; when several
+// windows match a student the most specific one applies.
+type BookingWindow struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TermID     uint      `json:"term_id" gorm:"not null;index"`
+	Department string    `json:"department,omitempty"`
+	Batch      int       `json:"batch,omitempty"`
+	OpensAt    time.Time `json:"opens_at" gorm:"not null"`
+	ClosesAt   time.Time `json:"closes_at" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Matches reports whether the window applies to student.
+func (w *BookingWindow) Matches(student *Student) bool {
+	return (w.Department == "" || w.Department == student.Department) &&
+		(w.Batch == 0 || w.Batch == student.Batch)
+}
+
+// Specificity ranks matching windows; department outranks batch.
+func (w *BookingWindow) Specificity() int {
+	score := 0
+	if w.Department != "" {
+		score += 2
+	}
+	if w.Batch != 0 {
+		score++
+	}
+	return score
+}
+
+// BookingPhase staggers the opening of a term's booking window. Students in
+// Batch (0 for any) with at least MinCGPA may book from StartsAt; a student
+// matching several phases gets the earliest. Once a term has phases, students
+// matching none of them cannot book.
+type BookingPhase struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TermID    uint      `json:"term_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"not null"`
+	Batch     int       `json:"batch,omitempty"`
+	MinCGPA   float64   `json:"min_cgpa,omitempty"`
+	StartsAt  time.Time `json:"starts_at" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Matches reports whether the phase applies to student.
+func (p *BookingPhase) Matches(student *Student) bool {
+	return (p.Batch == 0 || p.Batch == student.Batch) && student.CGPA >= p.MinCGPA
+}
+
+// BookingSchedule is when a student may book courses of a term. Phase names
+// the booking phase that set OpensAt, if any.
+type BookingSchedule struct {
+	OpensAt  time.Time `json:"opens_at"`
+	ClosesAt time.Time `json:"closes_at"`
+	Phase    string    `json:"phase,omitempty"`
+}
+
+// IsOpen reports whether booking is open at now.
+func (s *BookingSchedule) IsOpen(now time.Time) bool {
+	return !now.Before(s.OpensAt) && now.Before(s.ClosesAt)
+}
+
 type Course struct {
-	ID             uint        `json:"id" gorm:"primaryKey"`
-	Name           string      `json:"name" gorm:"not null"`
-	PDFLink        string      `json:"pdf_link"`
-	Rating         float64     `json:"rating" gorm:"default:0"`
-	SeatsBooked    StringArray `json:"seats_booked" gorm:"type:jsonb"`
-	StaffNames     StringArray `json:"staff_names" gorm:"type:jsonb"`
-	ImageLink      string      `json:"image_link"`
-	Description    string      `json:"description"`
-	Departments    StringArray `json:"departments" gorm:"type:jsonb"`
-	Genres         StringArray `json:"genres" gorm:"type:jsonb"`
-	CourseType     int         `json:"course_type" gorm:"not null"`
-	TotalSeats     int         `json:"total_seats" gorm:"not null"`
-	AvailableSeats int         `json:"available_seats"`
-	CreatedAt      time.Time   `json:"created_at"`
-	UpdatedAt      time.Time   `json:"updated_at"`
+	ID          uint        `json:"id" gorm:"primaryKey"`
+	Name        string      `json:"name" gorm:"not null"`
+	PDFLink     string      `json:"pdf_link"`
+	Rating      float64     `json:"rating" gorm:"default:0"`
+	SeatsBooked StringArray `json:"seats_booked" gorm:"type:jsonb"`
+	StaffNames  StringArray `json:"staff_names" gorm:"type:jsonb"`
+	ImageLink   string      `json:"image_link"`
+	Description string      `json:"description"`
+	Departments StringArray `json:"departments" gorm:"type:jsonb"`
+	Genres      StringArray `json:"genres" gorm:"type:jsonb"`
+	CourseType  int         `json:"course_type" gorm:"not null"`
+	TermID      uint        `json:"term_id" gorm:"not null;default:0;index"`
+	TotalSeats  int         `json:"total_seats" gorm:"not null"`
+
+	// DepartmentQuotas reserves seats for departments until QuotaReleaseAt,
+	// or for good if it is nil. See HasSeatFor. QuotaReleaseDone records
+	// that the waitlist has been promoted since the quotas lapsed.
+	DepartmentQuotas QuotaMap   `json:"department_quotas" gorm:"type:jsonb"`
+	QuotaReleaseAt   *time.Time `json:"quota_release_at,omitempty"`
+	QuotaReleaseDone bool       `json:"-" gorm:"not null;default:false"`
+
+	// Eligibility rules. Prerequisites must have been booked in a term that
+	// has ended; ExcludedCourses may not have been booked at all.
+	Prerequisites   IntArray `json:"prerequisites" gorm:"type:jsonb;default:'[]'"`
+	ExcludedCourses IntArray `json:"excluded_courses" gorm:"type:jsonb;default:'[]'"`
+	MinSemester     int      `json:"min_semester" gorm:"not null;default:0"`
+	MinCGPA         float64  `json:"min_cgpa" gorm:"not null;default:0"`
+
+	// Sessions is the weekly timetable of the course.
+	Sessions SessionList `json:"sessions" gorm:"type:jsonb;default:'[]'"`
+
+	AvailableSeats int       `json:"available_seats"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// Deleted courses are soft deleted so bookings keep their history.
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	CourseBookings []CourseBooking `json:"course_bookings,omitempty" gorm:"foreignKey:CourseID"`
 }
 
+// Sort keys for course listings
+const (
+	CourseSortRating    = "rating"
+	CourseSortName      = "name"
+	CourseSortSeatsLeft = "seats_left"
+)
+
+// CourseQuery filters, sorts and pages a course listing. Zero values mean no
+// filter; TermID is a pointer because 0 is the "no term" term. CourseTypes
+// restricts the listing to a set of categories and ExcludeBookedBy hides
+// courses booked by that student; both are set by the service rather than by
+// clients.
+type CourseQuery struct {
+	Search          string
+	Genre           string
+	Department      string
+	CourseType      int
+	TermID          *uint
+	CourseTypes     []int
+	ExcludeBookedBy uint
+	Staff           string
+	Available       *bool
+	EligibleFor     *Eligibility
+	Sort            string
+	Desc            bool
+	Page            int
+	Limit           int
+}
+
+// CoursePage is one page of a course listing. Total counts every matching
+// course across all pages.
+type CoursePage struct {
+	Courses []Course
+	Total   int64
+	Page    int
+	Limit   int
+
+	// Schedule is the student's booking schedule for the listed term, when
+	// listing available courses of a term that has one for them.
+	Schedule *BookingSchedule
+}
+
+type Seat struct {
+	SeatNo string `json:"seat_no"`
+	Booked bool   `json:"booked"`
+}
+
+// CourseDetail is a course together with its enrollment stats as seen by one
+// student.
+type CourseDetail struct {
+	Course           Course
+	BookingsByDept   map[string]int64
+	WaitlistLength   int64
+	Booked           bool
+	Eligible         bool
+	IneligibleReason string
+
+	// Quotas is empty unless the course reserves seats for departments.
+	Quotas        []DepartmentQuotaUsage
+	QuotaReleased bool
+}
+
+// DepartmentQuotaUsage is how much of one department's reserved seats are
+// taken.
+type DepartmentQuotaUsage struct {
+	Department string
+	Quota      int
+	Booked     int64
+}
+
+// CourseUpdate holds the fields of a partial course update. Nil fields are
+// left unchanged.
+type CourseUpdate struct {
+	Name        *string
+	PDFLink     *string
+	Rating      *float64
+	StaffNames  *StringArray
+	ImageLink   *string
+	Description *string
+	Departments *StringArray
+	Genres      *StringArray
+	CourseType  *int
+	TermID      *uint
+	TotalSeats  *int
+
+	DepartmentQuotas *QuotaMap
+	QuotaReleaseAt   NullableTime
+
+	Prerequisites   *IntArray
+	ExcludedCourses *IntArray
+	MinSemester     *int
+	MinCGPA         *float64
+
+	Sessions *SessionList
+}
+
+// Eligibility is a student's standing as seen by course eligibility rules.
+type Eligibility struct {
+	Semester int
+	CGPA     float64
+
+	// Completed holds the courses booked in terms that have ended and Taken
+	// every booked course. Excluded maps the courses excluded by a taken
+	// course to that course.
+	Completed map[uint]bool
+	Taken     map[uint]bool
+	Excluded  map[uint]uint
+}
+
+func NewEligibility(student *Student) *Eligibility {
+	return &Eligibility{
+		Semester:  student.Semester,
+		CGPA:      student.CGPA,
+		Completed: make(map[uint]bool),
+		Taken:     make(map[uint]bool),
+		Excluded:  make(map[uint]uint),
+	}
+}
+
+// AddBooking records a booking of course, completed if its term has ended.
+func (e *Eligibility) AddBooking(course *Course, completed bool) {
+	e.Taken[course.ID] = true
+	if completed {
+		e.Completed[course.ID] = true
+	}
+	for _, id := range course.ExcludedCourses {
+		e.Excluded[uint(id)] = course.ID
+	}
+}
+
+// Allows reports whether the student meets every eligibility rule of course.
+func (e *Eligibility) Allows(course *Course) bool {
+	return e.Semester >= course.MinSemester &&
+		e.CGPA >= course.MinCGPA &&
+		len(e.MissingPrerequisites(course)) == 0 &&
+		len(e.Conflicts(course)) == 0
+}
+
+// MissingPrerequisites returns the prerequisites of course that the student
+// has not completed.
+func (e *Eligibility) MissingPrerequisites(course *Course) []uint {
+	var missing []uint
+	for _, id := range course.Prerequisites {
+		if !e.Completed[uint(id)] {
+			missing = append(missing, uint(id))
+		}
+	}
+	return missing
+}
+
+// Conflicts returns the taken courses that rule out course, whichever of the
+// two lists the exclusion.
+func (e *Eligibility) Conflicts(course *Course) []uint {
+	var conflicts []uint
+	for _, id := range course.ExcludedCourses {
+		if e.Taken[uint(id)] {
+			conflicts = append(conflicts, uint(id))
+		}
+	}
+	if id, ok := e.Excluded[course.ID]; ok && !course.Excludes(id) {
+		conflicts = append(conflicts, id)
+	}
+	return conflicts
+}
+
+// Sections lists the course's section names in order of first appearance.
+// A course whose sessions name no section has none.
+func (c *Course) Sections() []string {
+	var sections []string
+	seen := make(map[string]bool)
+	for _, session := range c.Sessions {
+		if session.Section != "" && !seen[session.Section] {
+			seen[session.Section] = true
+			sections = append(sections, session.Section)
+		}
+	}
+	return sections
+}
+
+// SectionSessions returns the sessions a student of section attends.
+func (c *Course) SectionSessions(section string) []Session {
+	sessions := make([]Session, 0, len(c.Sessions))
+	for _, session := range c.Sessions {
+		if session.Section == "" || session.Section == section {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// ClashWith returns the first pair of sessions at which section of the
+// course and otherSection of other meet at the same time.
+func (c *Course) ClashWith(section string, other *Course, otherSection string) (Session, Session, bool) {
+	for _, session := range c.SectionSessions(section) {
+		for _, otherSession := range other.SectionSessions(otherSection) {
+			if session.Overlaps(otherSession) {
+				return session, otherSession, true
+			}
+		}
+	}
+	return Session{}, Session{}, false
+}
+
+// Excludes reports whether courseID is one of the course's excluded courses.
+func (c *Course) Excludes(courseID uint) bool {
+	for _, id := range c.ExcludedCourses {
+		if uint(id) == courseID {
+			return true
+		}
+	}
+	return false
+}
+
+// SeatsLeft returns how many seats can still be booked.
+func (c *Course) SeatsLeft() int {
+	left := c.TotalSeats - len(c.SeatsBooked)
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// IsFull reports whether every seat of the course has been booked.
+func (c *Course) IsFull() bool {
+	return c.SeatsLeft() == 0
+}
+
+// ReleaseSeat removes seatNo from the booked seats.
+func (c *Course) ReleaseSeat(seatNo string) {
+	seats := make(StringArray, 0, len(c.SeatsBooked))
+	for _, bookedSeat := range c.SeatsBooked {
+		if bookedSeat != seatNo {
+			seats = append(seats, bookedSeat)
+		}
+	}
+	c.SeatsBooked = seats
+}
+
+// QuotasActive reports whether department quotas apply at now.
+func (c *Course) QuotasActive(now time.Time) bool {
+	return len(c.DepartmentQuotas) > 0 && (c.QuotaReleaseAt == nil || now.Before(*c.QuotaReleaseAt))
+}
+
+// QuotaReleaseDue reports whether the course's quotas have lapsed at now and
+// its waitlist has not been promoted for that yet.
+func (c *Course) QuotaReleaseDue(now time.Time) bool {
+	return len(c.DepartmentQuotas) > 0 && c.QuotaReleaseAt != nil && !now.Before(*c.QuotaReleaseAt) && !c.QuotaReleaseDone
+}
+
+// HasSeatFor reports whether a student of department may take a free seat,
+// given the course's bookings per department. A department first uses its own
+// quota; beyond that it competes with everyone for the seats outside all
+// quotas. The caller still has to check that the course is not full.
+func (c *Course) HasSeatFor(department string, booked map[string]int64, now time.Time) bool {
+	if !c.QuotasActive(now) {
+		return true
+	}
+	if booked[department] < int64(c.DepartmentQuotas[department]) {
+		return true
+	}
+
+	open := int64(c.TotalSeats)
+	for _, quota := range c.DepartmentQuotas {
+		open -= int64(quota)
+	}
+	for d, count := range booked {
+		if over := count - int64(c.DepartmentQuotas[d]); over > 0 {
+			open -= over
+		}
+	}
+	return open > 0
+}
+
+// QuotaTotal returns the number of seats reserved by department quotas.
+func (c *Course) QuotaTotal() int {
+	total := 0
+	for _, quota := range c.DepartmentQuotas {
+		total += quota
+	}
+	return total
+}
+
+func (c *Course) HasDepartment(department string) bool {
+	for _, d := range c.Departments {
+		if d == department {
+			return true
+		}
+	}
+	return false
+}
+
+// SeatMap lists every seat of the course in order with its booking state.
+func (c *Course) SeatMap() []Seat {
+	seats := make([]Seat, 0, c.TotalSeats)
+	for n := 1; n <= c.TotalSeats; n++ {
+		seatNo := strconv.Itoa(n)
+		seats = append(seats, Seat{SeatNo: seatNo, Booked: c.IsSeatBooked(seatNo)})
+	}
+	return seats
+}
+
+// IsSeatBooked reports whether seatNo is already taken.
+func (c *Course) IsSeatBooked(seatNo string) bool {
+	for _, bookedSeat := range c.SeatsBooked {
+		if bookedSeat == seatNo {
+			return true
+		}
+	}
+	return false
+}
+
+// TimetableEntry is one weekly session of a course the student has booked.
+type TimetableEntry struct {
+	Session
+	CourseID   uint
+	CourseName string
+	SeatNo     string
+}
+
+// Timetable is a student's week in one term, ordered by day and start time.
+// Term is nil for courses outside any term.
+type Timetable struct {
+	Term    *AcademicTerm
+	Entries []TimetableEntry
+}
+
 type CourseBooking struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	StudentID uint      `json:"student_id" gorm:"not null"`
 	CourseID  uint      `json:"course_id" gorm:"not null"`
+	TermID    uint      `json:"term_id" gorm:"not null;default:0;index"`
 	SeatNo    string    `json:"seat_no"`
+	Section   string    `json:"section,omitempty" gorm:"not null;default:''"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Cancelled bookings are soft deleted so the history is kept.
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	CancelledBy *uint          `json:"cancelled_by,omitempty"`
+
+	// Relations
+	Student Student `json:"student,omitempty" gorm:"foreignKey:StudentID"`
+	Course  Course  `json:"course,omitempty" gorm:"foreignKey:CourseID"`
+}
+
+// Waitlist is a student's place in the queue for a full course. Entries are
+// served in CreatedAt order.
+type Waitlist struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	StudentID uint      `json:"student_id" gorm:"not null;uniqueIndex:idx_waitlist_student_course"`
+	CourseID  uint      `json:"course_id" gorm:"not null;uniqueIndex:idx_waitlist_student_course;index"`
 	CreatedAt time.Time `json:"created_at"`
 
 	// Relations
@@ -74,11 +834,75 @@ type CourseBooking struct {
 	Course  Course  `json:"course,omitempty" gorm:"foreignKey:CourseID"`
 }
 
+// RefreshToken is a long-lived, single-use credential for obtaining new access
+// tokens. Only a hash of the token is stored. Every rotation stays in the
+// family started at login so a replayed token can revoke the whole chain.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	StudentID uint       `json:"student_id" gorm:"not null;index"`
+	FamilyID  string     `json:"family_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// PasswordResetToken is a single-use token mailed to a student who forgot
+// their password. Only a hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	StudentID uint       `json:"student_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// LoginAttempt tracks consecutive failed logins for one key, which is either
+// an account ("account:<register_no>") or a client address ("ip:<addr>").
+type LoginAttempt struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Key           string     `json:"key" gorm:"not null;uniqueIndex"`
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// IsLocked reports whether the key is locked at the given time.
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
+
+// TokenPair is what a successful login or refresh hands back to the client.
+type TokenPair struct {
+	AccessToken  string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+}
+
+// JSONWebKey is the public part of a signing key as published in the JWKS
+// document (RFC 7517).
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
 type StudentEntity struct {
-	ID         uint   `json:"id"`
-	RegisterNo string `json:"register_no"`
-	Department string `json:"department"`
-	Name       string `json:"name"`
+	ID         uint    `json:"id"`
+	RegisterNo string  `json:"register_no"`
+	Department string  `json:"department"`
+	Name       string  `json:"name"`
+	Batch      int     `json:"batch"`
+	Semester   int     `json:"semester"`
+	CGPA       float64 `json:"cgpa"`
+	Role       string  `json:"role"`
 }
 
 type CourseEntity struct {
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCourseQuotas(t *testing.T) {
+	release := time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)
+	before := release.Add(-time.Minute)
+
+	// 10 seats: 4 held for CSE, 3 for ECE and 3 open to everyone
+	quotas := QuotaMap{"CSE": 4, "ECE": 3}
+
+	tests := []struct {
+		name       string
+		quotas     QuotaMap
+		releaseAt  *time.Time
+		done       bool
+		now        time.Time
+		department string
+		booked     map[string]int64
+		active     bool
+		due        bool
+		hasSeat    bool
+	}{
+		{
+			name:       "no quotas",
+			now:        before,
+			department: "MECH",
+			booked:     map[string]int64{"CSE": 9},
+			hasSeat:    true,
+		},
+		{
+			name:       "own quota left",
+			quotas:     quotas,
+			releaseAt:  &release,
+			now:        before,
+			department: "CSE",
+			booked:     map[string]int64{"CSE": 3, "MECH": 3},
+			active:     true,
+			hasSeat:    true,
+		},
+		{
+			name:       "own quota used, open seats left",
+			quotas:     quotas,
+			releaseAt:  &release,
+			now:        before,
+			department: "CSE",
+			booked:     map[string]int64{"CSE": 6},
+			active:     true,
+			hasSeat:    true,
+		},
+		{
+			name:       "open seats taken by another department's overflow",
+			quotas:     quotas,
+			releaseAt:  &release,
+			now:        before,
+			department: "CSE",
+			booked:     map[string]int64{"CSE": 4, "ECE": 6},
+			active:     true,
+		},
+		{
+			name:       "department without a quota shares the open seats",
+			quotas:     quotas,
+			releaseAt:  &release,
+			now:        before,
+			department: "MECH",
+			booked:     map[string]int64{"MECH": 3},
+			active:     true,
+		},
+		{
+			name:       "quotas without a release time never lapse",
+			quotas:     quotas,
+			now:        release.AddDate(1, 0, 0),
+			department: "MECH",
+			booked:     map[string]int64{"MECH": 3},
+			active:     true,
+		},
+		{
+			name:       "seats are open to everyone at the release time",
+			quotas:     quotas,
+			releaseAt:  &release,
+			now:        release,
+			department: "MECH",
+			booked:     map[string]int64{"MECH": 3},
+			due:        true,
+			hasSeat:    true,
+		},
+		{
+			name:       "release already promoted",
+			quotas:     quotas,
+			releaseAt:  &release,
+			done:       true,
+			now:        release.Add(time.Hour),
+			department: "MECH",
+			booked:     map[string]int64{"MECH": 3},
+			hasSeat:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			course := &Course{
+				TotalSeats:       10,
+				DepartmentQuotas: tt.quotas,
+				QuotaReleaseAt:   tt.releaseAt,
+				QuotaReleaseDone: tt.done,
+			}
+			if got := course.QuotasActive(tt.now); got != tt.active {
+				t.Errorf("QuotasActive() = %v, want %v", got, tt.active)
+			}
+			if got := course.QuotaReleaseDue(tt.now); got != tt.due {
+				t.Errorf("QuotaReleaseDue() = %v, want %v", got, tt.due)
+			}
+			if got := course.HasSeatFor(tt.department, tt.booked, tt.now); got != tt.hasSeat {
+				t.Errorf("HasSeatFor(%q) = %v, want %v", tt.department, got, tt.hasSeat)
+			}
+		})
+	}
+}
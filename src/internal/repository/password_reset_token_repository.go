@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type passwordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) domain.PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: db}
+}
+
+func (r *passwordResetTokenRepository) Create(token *models.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHashForUpdate loads the token and holds a row lock on it until the
+// surrounding transaction ends, so a token cannot be redeemed twice.
+func (r *passwordResetTokenRepository) GetByHashForUpdate(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *passwordResetTokenRepository) MarkUsed(id uint) error {
+	return r.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now()).Error
+}
+
+// InvalidateByStudentID marks every outstanding token of the student as used.
+func (r *passwordResetTokenRepository) InvalidateByStudentID(studentID uint) error {
+	return r.db.Model(&models.PasswordResetToken{}).
+		Where("student_id = ? AND used_at IS NULL", studentID).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type rosterRepository struct {
+	db *gorm.DB
+}
+
+func NewRosterRepository(db *gorm.DB) domain.RosterRepository {
+	return &rosterRepository{db: db}
+}
+
+// Upsert inserts the entries, replacing the name, department and batch of
+// register numbers that are already on the roster along with the optional
+// columns, "semester" and "cgpa", that the import provided.
+func (r *rosterRepository) Upsert(entries []models.RosterEntry, optional []string) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	columns := append([]string{"name", "department", "batch"}, optional...)
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "register_no"}},
+		DoUpdates: clause.AssignmentColumns(append(columns, "updated_at")),
+	}).CreateInBatches(entries, 500).Error
+}
+
+func (r *rosterRepository) GetByRegisterNo(registerNo string) (*models.RosterEntry, error) {
+	var entry models.RosterEntry
+	err := r.db.Where("register_no = ?", registerNo).First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *rosterRepository) GetAll() ([]models.RosterEntry, error) {
+	var entries []models.RosterEntry
+	err := r.db.Order("register_no").Find(&entries).Error
+	return entries, err
+}
+
+// SyncStudents copies the roster department and batch, and the optional
+// columns the import provided, onto already registered students with the
+// given register numbers.
+func (r *rosterRepository) SyncStudents(registerNos []string, optional []string) error {
+	if len(registerNos) == 0 {
+		return nil
+	}
+	assignments := []string{"department = roster_entries.department", "batch = roster_entries.batch"}
+	for _, column := range optional {
+		assignments = append(assignments, column+" = roster_entries."+column)
+	}
+	return r.db.Exec(`
+		UPDATE students SET `+strings.Join(assignments, ", ")+`
+		FROM roster_entries
+		WHERE students.register_no = roster_entries.register_no AND roster_entries.register_no IN ?`,
+		registerNos,
+	).Error
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/sk/elective/src/internal/domain"
+	"gorm.io/gorm"
+)
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) domain.UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(fn func(repos *domain.Repositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(newRepositories(tx))
+	})
+}
+
+func newRepositories(db *gorm.DB) *domain.Repositories {
+	return &domain.Repositories{
+		Students:    NewStudentRepository(db),
+		Roster:      NewRosterRepository(db),
+		Categories:  NewElectiveCategoryRepository(db),
+		Terms:       NewAcademicTermRepository(db),
+		Windows:     NewBookingWindowRepository(db),
+		Phases:      NewBookingPhaseRepository(db),
+		Courses:     NewCourseRepository(db),
+		Bookings:    NewCourseBookingRepository(db),
+		Waitlists:   NewWaitlistRepository(db),
+		Preferences: NewCoursePreferenceRepository(db),
+
+		RefreshTokens:       NewRefreshTokenRepository(db),
+		PasswordResetTokens: NewPasswordResetTokenRepository(db),
+	}
+}
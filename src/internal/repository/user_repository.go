@@ -4,6 +4,7 @@ import (
 	"github.com/sk/elective/src/internal/domain"
 	"github.com/sk/elective/src/internal/repository/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type studentRepository struct {
@@ -18,6 +19,10 @@ func (r *studentRepository) Create(student *models.Student) error {
 	return r.db.Create(student).Error
 }
 
+func (r *studentRepository) Update(student *models.Student) error {
+	return r.db.Save(student).Error
+}
+
 func (r *studentRepository) GetByRegisterNo(registerNo string) (*models.Student, error) {
 	var student models.Student
 	err := r.db.Where("register_no = ?", registerNo).First(&student).Error
@@ -34,4 +39,38 @@ func (r *studentRepository) GetByID(id uint) (*models.Student, error) {
 		return nil, err
 	}
 	return &student, nil
-}
\ No newline at end of file
+}
+
+// GetByIDForUpdate loads the student and holds a row lock on it until the
+// surrounding transaction ends.
+func (r *studentRepository) GetByIDForUpdate(id uint) (*models.Student, error) {
+	var student models.Student
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&student, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &student, nil
+}
+
+// GetByIDForUpdateSkipLocked is like GetByIDForUpdate but returns
+// gorm.ErrRecordNotFound instead of waiting when another transaction holds
+// the lock.
+func (r *studentRepository) GetByIDForUpdateSkipLocked(id uint) (*models.Student, error) {
+	var student models.Student
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).First(&student, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &student, nil
+}
+
+// GetByIDsForUpdate loads and locks the students in id order, so that callers
+// locking overlapping sets cannot deadlock.
+func (r *studentRepository) GetByIDsForUpdate(ids []uint) ([]models.Student, error) {
+	var students []models.Student
+	if len(ids) == 0 {
+		return students, nil
+	}
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id").Find(&students).Error
+	return students, err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type waitlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWaitlistRepository(db *gorm.DB) domain.WaitlistRepository {
+	return &waitlistRepository{db: db}
+}
+
+func (r *waitlistRepository) Create(entry *models.Waitlist) error {
+	return r.db.Create(entry).Error
+}
+
+// Delete removes the student from the course's waitlist. It returns
+// gorm.ErrRecordNotFound if the student was not waitlisted.
+func (r *waitlistRepository) Delete(studentID uint, courseID uint) error {
+	result := r.db.Where("student_id = ? AND course_id = ?", studentID, courseID).Delete(&models.Waitlist{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *waitlistRepository) GetByStudentAndCourse(studentID uint, courseID uint) (*models.Waitlist, error) {
+	var entry models.Waitlist
+	err := r.db.Where("student_id = ? AND course_id = ?", studentID, courseID).First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *waitlistRepository) GetByCourseID(courseID uint) ([]models.Waitlist, error) {
+	var entries []models.Waitlist
+	err := r.db.Where("course_id = ?", courseID).Order("created_at, id").Find(&entries).Error
+	return entries, err
+}
+
+func (r *waitlistRepository) DeleteByCourseID(courseID uint) error {
+	return r.db.Where("course_id = ?", courseID).Delete(&models.Waitlist{}).Error
+}
+
+func (r *waitlistRepository) CountByCourseID(courseID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Waitlist{}).Where("course_id = ?", courseID).Count(&count).Error
+	return count, err
+}
+
+// Position returns the 1-based place of entry in its course's queue.
+func (r *waitlistRepository) Position(entry *models.Waitlist) (int64, error) {
+	var ahead int64
+	err := r.db.Model(&models.Waitlist{}).
+		Where("course_id = ? AND (created_at < ? OR (created_at = ? AND id < ?))",
+			entry.CourseID, entry.CreatedAt, entry.CreatedAt, entry.ID).
+		Count(&ahead).Error
+	return ahead + 1, err
+}
@@ -0,0 +1,475 @@
+package usecase
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/sk/elective/src/internal/config"
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+type allocationService struct {
+	termRepo       domain.AcademicTermRepository
+	preferenceRepo domain.CoursePreferenceRepository
+	uow            domain.UnitOfWork
+	assignSeat     seatAssigner
+}
+
+// NewAllocationService builds the preference allocation service. Allocated
+// seats are placed with the same checks and seat strategy as direct bookings.
+func NewAllocationService(termRepo domain.AcademicTermRepository, preferenceRepo domain.CoursePreferenceRepository, uow domain.UnitOfWork, bookingConfig config.BookingConfig) (domain.AllocationService, error) {
+	assignSeat, err := newSeatAssigner(bookingConfig.SeatStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &allocationService{
+		termRepo:       termRepo,
+		preferenceRepo: preferenceRepo,
+		uow:            uow,
+		assignSeat:     assignSeat,
+	}, nil
+}
+
+// SubmitPreferences replaces the student's ranked course choices for a
+// category of a preference term. termID 0 means the active term. Preferences
+// can be changed while the student's booking window is open.
+func (s *allocationService) SubmitPreferences(student *models.Student, termID uint, categoryID uint, courseIDs []uint) ([]models.CoursePreference, error) {
+	var preferences []models.CoursePreference
+	err := s.uow.Do(func(repos *domain.Repositories) error {
+		term, err := s.preferenceTerm(repos, termID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkBookingWindow(repos, student, term.ID, time.Now()); err != nil {
+			return err
+		}
+
+		category, err := repos.Categories.GetByID(categoryID)
+		if err != nil {
+			return notFoundAs(err, domain.ErrCategoryNotFound)
+		}
+		if !category.AllowsSemester(student.Semester) {
+			return domain.ErrSemesterIneligible.WithMessage(fmt.Sprintf("%s is not open to semester %d students", category.Name, student.Semester))
+		}
+
+		eligibility, err := loadEligibility(repos.Bookings, repos.Terms, student, time.Now())
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[uint]bool, len(courseIDs))
+		for i, courseID := range courseIDs {
+			if seen[courseID] {
+				return domain.ErrInvalidPreference.WithMessage(fmt.Sprintf("course %d is listed more than once", courseID))
+			}
+			seen[courseID] = true
+
+			course, err := repos.Courses.GetByID(courseID)
+			if err != nil {
+				return notFoundAs(err, domain.ErrCourseNotFound.WithMessage(fmt.Sprintf("course %d not found", courseID)))
+			}
+			if course.TermID != term.ID || course.CourseType != int(category.ID) {
+				return domain.ErrInvalidPreference.WithMessage(fmt.Sprintf("%s is not a %s course of %s", course.Name, category.Name, term.Name))
+			}
+			if !course.HasDepartment(student.Department) {
+				return domain.ErrInvalidPreference.WithMessage(fmt.Sprintf("%s is not offered to your department", course.Name))
+			}
+			if err := checkCourseRules(repos.Courses, eligibility, course); err != nil {
+				return err
+			}
+
+			preferences = append(preferences, models.CoursePreference{
+				StudentID:  student.ID,
+				TermID:     term.ID,
+				CategoryID: category.ID,
+				CourseID:   course.ID,
+				Rank:       i + 1,
+			})
+		}
+
+		if err := repos.Preferences.DeleteByStudentAndCategory(student.ID, term.ID, category.ID); err != nil {
+			return err
+		}
+		return repos.Preferences.CreateBatch(preferences)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (s *allocationService) GetPreferences(studentID uint, termID uint) ([]models.CoursePreference, error) {
+	if termID == 0 {
+		term, err := s.termRepo.GetActive()
+		if err != nil {
+			return nil, notFoundAs(err, domain.ErrNoActiveTerm)
+		}
+		termID = term.ID
+	}
+	return s.preferenceRepo.GetByStudentAndTerm(studentID, termID)
+}
+
+// preferenceTerm loads the term, or the active one for termID 0, and checks
+// that it is still waiting for its allocation.
+func (s *allocationService) preferenceTerm(repos *domain.Repositories, termID uint) (*models.AcademicTerm, error) {
+	var term *models.AcademicTerm
+	var err error
+	if termID == 0 {
+		term, err = repos.Terms.GetActive()
+		err = notFoundAs(err, domain.ErrNoActiveTerm)
+	} else {
+		term, err = repos.Terms.GetByID(termID)
+		err = notFoundAs(err, domain.ErrTermNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if term.AllocationMode != models.AllocationPreference {
+		return nil, domain.ErrNotPreferenceTerm
+	}
+	if term.AllocatedAt != nil {
+		return nil, domain.ErrAllocationDone
+	}
+	return term, nil
+}
+
+// RunAllocation assigns seats of a preference term from the submitted
+// preferences. Students are served one at a time in order of CGPA, ties broken
+// by a lottery drawn from seed, and each gets their best ranked courses that
+// still have seats open to them, meet their eligibility rules and fit their
+// timetable, up to their category limits. With a common priority order
+// this serial dictatorship yields the stable matching.
+//
+// Unless commit is set nothing is written and the report only shows what the
+// run would do. A commit is refused while any booking window of the term is
+// still open. A seed of 0 draws a fresh one; rerunning with the seed of a
+// dry run reproduces it as long as preferences and seats have not changed.
+func (s *allocationService) RunAllocation(termID uint, seed int64, commit bool) (*models.AllocationReport, error) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	var report *models.AllocationReport
+	err := s.uow.Do(func(repos *domain.Repositories) error {
+		term, err := s.preferenceTerm(repos, termID)
+		if err != nil {
+			return err
+		}
+
+		if commit {
+			if err := checkPreferencesClosed(repos, term.ID, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		run, err := loadAllocationRun(repos, term.ID)
+		if err != nil {
+			return err
+		}
+
+		report = run.allocate(rand.New(rand.NewSource(seed)))
+		report.TermID = term.ID
+		report.Seed = seed
+		if !commit {
+			return nil
+		}
+
+		for i := range report.Assignments {
+			assignment := &report.Assignments[i]
+			booking, err := placeBooking(repos, s.assignSeat, run.students[assignment.StudentID], run.courses[assignment.CourseID], "", assignment.Section)
+			if err != nil {
+				return fmt.Errorf("allocating course %d to student %s: %w", assignment.CourseID, assignment.RegisterNo, err)
+			}
+			assignment.SeatNo = booking.SeatNo
+		}
+
+		now := time.Now()
+		term.AllocatedAt = &now
+		if err := repos.Terms.Update(term); err != nil {
+			return err
+		}
+		report.Committed = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// checkPreferencesClosed checks that every booking window of the term has
+// closed, so that no student can still submit preferences the allocation
+// would miss.
+func checkPreferencesClosed(repos *domain.Repositories, termID uint, now time.Time) error {
+	windows, err := repos.Windows.GetByTermID(termID)
+	if err != nil {
+		return err
+	}
+
+	var lastClose time.Time
+	for _, window := range windows {
+		if window.ClosesAt.After(lastClose) {
+			lastClose = window.ClosesAt
+		}
+	}
+	if now.Before(lastClose) {
+		return domain.ErrPreferencesOpen.WithMessage(fmt.Sprintf("preferences can be submitted until %s, commit the allocation after that", lastClose.Format(time.RFC3339)))
+	}
+	return nil
+}
+
+// allocationRun holds the locked state of a term that an allocation works on.
+type allocationRun struct {
+	students    map[uint]*models.Student
+	courses     map[uint]*models.Course
+	courseOrder []uint
+	categories  map[uint]*models.ElectiveCategory
+	preferences map[uint][]models.CoursePreference
+	booked      map[uint]map[uint]bool
+	counts      map[uint]map[uint]int
+	byDept      map[uint]map[string]int64
+	eligibility map[uint]*models.Eligibility
+	held        map[uint][]heldSection
+	now         time.Time
+}
+
+// heldSection is a course section a student holds during an allocation.
+type heldSection struct {
+	course  *models.Course
+	section string
+}
+
+// loadAllocationRun locks the students with preferences and then the courses
+// of the term, in the same order as direct bookings take their locks.
+func loadAllocationRun(repos *domain.Repositories, termID uint) (*allocationRun, error) {
+	preferences, err := repos.Preferences.GetByTermID(termID)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &allocationRun{
+		students:    make(map[uint]*models.Student),
+		courses:     make(map[uint]*models.Course),
+		categories:  make(map[uint]*models.ElectiveCategory),
+		preferences: make(map[uint][]models.CoursePreference),
+		booked:      make(map[uint]map[uint]bool),
+		counts:      make(map[uint]map[uint]int),
+		byDept:      make(map[uint]map[string]int64),
+		eligibility: make(map[uint]*models.Eligibility),
+		held:        make(map[uint][]heldSection),
+		now:         time.Now(),
+	}
+
+	var studentIDs []uint
+	for _, preference := range preferences {
+		if _, ok := run.preferences[preference.StudentID]; !ok {
+			studentIDs = append(studentIDs, preference.StudentID)
+		}
+		run.preferences[preference.StudentID] = append(run.preferences[preference.StudentID], preference)
+	}
+
+	students, err := repos.Students.GetByIDsForUpdate(studentIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range students {
+		run.students[students[i].ID] = &students[i]
+		run.eligibility[students[i].ID] = models.NewEligibility(&students[i])
+	}
+
+	ended, err := endedTerms(repos.Terms, run.now)
+	if err != nil {
+		return nil, err
+	}
+	history, err := repos.Bookings.GetByStudentIDs(studentIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range history {
+		if eligibility, ok := run.eligibility[history[i].StudentID]; ok {
+			eligibility.AddBooking(&history[i].Course, ended[history[i].TermID])
+		}
+	}
+
+	courses, err := repos.Courses.GetByTermIDForUpdate(termID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range courses {
+		run.courses[courses[i].ID] = &courses[i]
+		run.courseOrder = append(run.courseOrder, courses[i].ID)
+
+		if courses[i].QuotasActive(run.now) {
+			if run.byDept[courses[i].ID], err = repos.Bookings.CountByDepartment(courses[i].ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	categories, err := repos.Categories.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range categories {
+		run.categories[categories[i].ID] = &categories[i]
+	}
+
+	bookings, err := repos.Bookings.GetByTermID(termID)
+	if err != nil {
+		return nil, err
+	}
+	for _, booking := range bookings {
+		course, ok := run.courses[booking.CourseID]
+		if !ok {
+			continue
+		}
+		run.markBooked(booking.StudentID, course, booking.Section)
+	}
+
+	return run, nil
+}
+
+func (r *allocationRun) markBooked(studentID uint, course *models.Course, section string) {
+	if r.booked[studentID] == nil {
+		r.booked[studentID] = make(map[uint]bool)
+		r.counts[studentID] = make(map[uint]int)
+	}
+	r.booked[studentID][course.ID] = true
+	r.counts[studentID][uint(course.CourseType)]++
+	r.held[studentID] = append(r.held[studentID], heldSection{course: course, section: section})
+}
+
+// fitSection returns the first section of course that does not meet at the
+// same time as one the student already holds in the term.
+func (r *allocationRun) fitSection(studentID uint, course *models.Course) (string, bool) {
+	sections := course.Sections()
+	if len(sections) == 0 {
+		sections = []string{""}
+	}
+	for _, section := range sections {
+		if !r.clashes(studentID, course, section) {
+			return section, true
+		}
+	}
+	return "", false
+}
+
+func (r *allocationRun) clashes(studentID uint, course *models.Course, section string) bool {
+	for _, held := range r.held[studentID] {
+		if _, _, ok := course.ClashWith(section, held.course, held.section); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// priorityOrder returns the students by descending CGPA. The lottery shuffle
+// comes first so that the stable sort leaves equal CGPAs in lottery order.
+func (r *allocationRun) priorityOrder(lottery *rand.Rand) []*models.Student {
+	order := make([]*models.Student, 0, len(r.students))
+	for _, student := range r.students {
+		order = append(order, student)
+	}
+	// Start from a fixed order so that the seed alone decides the shuffle
+	sort.Slice(order, func(i, j int) bool { return order[i].ID < order[j].ID })
+	lottery.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	sort.SliceStable(order, func(i, j int) bool { return order[i].CGPA > order[j].CGPA })
+	return order
+}
+
+func (r *allocationRun) allocate(lottery *rand.Rand) *models.AllocationReport {
+	report := &models.AllocationReport{
+		Assignments: []models.AllocationAssignment{},
+		Unfilled:    []models.AllocationShortfall{},
+	}
+	allocated := make(map[uint]int)
+
+	for _, student := range r.priorityOrder(lottery) {
+		preferences := r.preferences[student.ID]
+
+		// Preferences are sorted by category and rank
+		for start := 0; start < len(preferences); {
+			categoryID := preferences[start].CategoryID
+			end := start
+			for end < len(preferences) && preferences[end].CategoryID == categoryID {
+				end++
+			}
+			ranked := preferences[start:end]
+			start = end
+
+			category, ok := r.categories[categoryID]
+			if !ok || !category.AllowsSemester(student.Semester) {
+				continue
+			}
+
+			remaining := category.MaxSelections - r.counts[student.ID][categoryID]
+			for _, preference := range ranked {
+				if remaining <= 0 {
+					break
+				}
+
+				course, ok := r.courses[preference.CourseID]
+				if !ok || course.CourseType != int(categoryID) || !course.HasDepartment(student.Department) {
+					continue
+				}
+				if r.booked[student.ID][course.ID] || course.SeatsLeft()-allocated[course.ID] <= 0 {
+					continue
+				}
+				if !course.HasSeatFor(student.Department, r.byDept[course.ID], r.now) {
+					continue
+				}
+				if !r.eligibility[student.ID].Allows(course) {
+					continue
+				}
+				section, ok := r.fitSection(student.ID, course)
+				if !ok {
+					continue
+				}
+
+				allocated[course.ID]++
+				if r.byDept[course.ID] != nil {
+					r.byDept[course.ID][student.Department]++
+				}
+				r.markBooked(student.ID, course, section)
+				r.eligibility[student.ID].AddBooking(course, false)
+				remaining--
+				report.Assignments = append(report.Assignments, models.AllocationAssignment{
+					StudentID:  student.ID,
+					RegisterNo: student.RegisterNo,
+					CategoryID: categoryID,
+					CourseID:   course.ID,
+					Section:    section,
+					Rank:       preference.Rank,
+				})
+			}
+
+			if remaining > 0 {
+				report.Unfilled = append(report.Unfilled, models.AllocationShortfall{
+					StudentID:  student.ID,
+					RegisterNo: student.RegisterNo,
+					CategoryID: categoryID,
+					Missing:    remaining,
+				})
+			}
+		}
+	}
+
+	for _, courseID := range r.courseOrder {
+		course := r.courses[courseID]
+		report.Courses = append(report.Courses, models.AllocationCourse{
+			CourseID:   course.ID,
+			Name:       course.Name,
+			TotalSeats: course.TotalSeats,
+			Booked:     len(course.SeatsBooked),
+			Allocated:  allocated[course.ID],
+		})
+	}
+
+	return report
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+func newTestRun(students []models.Student) *allocationRun {
+	run := &allocationRun{
+		students:    make(map[uint]*models.Student),
+		courses:     make(map[uint]*models.Course),
+		categories:  make(map[uint]*models.ElectiveCategory),
+		preferences: make(map[uint][]models.CoursePreference),
+		booked:      make(map[uint]map[uint]bool),
+		counts:      make(map[uint]map[uint]int),
+		byDept:      make(map[uint]map[string]int64),
+		eligibility: make(map[uint]*models.Eligibility),
+		held:        make(map[uint][]heldSection),
+		now:         time.Now(),
+	}
+	for i := range students {
+		run.students[students[i].ID] = &students[i]
+		run.eligibility[students[i].ID] = models.NewEligibility(&students[i])
+	}
+	return run
+}
+
+// lotteryOrder replays the shuffle priorityOrder makes for seed and returns
+// the ids among ties in the order it leaves them.
+func lotteryOrder(seed int64, ids []uint, ties map[uint]bool) []uint {
+	order := append([]uint(nil), ids...)
+	rand.New(rand.NewSource(seed)).Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	var tied []uint
+	for _, id := range order {
+		if ties[id] {
+			tied = append(tied, id)
+		}
+	}
+	return tied
+}
+
+func TestPriorityOrder(t *testing.T) {
+	students := []models.Student{
+		{ID: 1, CGPA: 8.0},
+		{ID: 2, CGPA: 9.5},
+		{ID: 3, CGPA: 8.0},
+		{ID: 4, CGPA: 6.5},
+		{ID: 5, CGPA: 8.0},
+		{ID: 6, CGPA: 8.0},
+	}
+	ids := []uint{1, 2, 3, 4, 5, 6}
+	ties := map[uint]bool{1: true, 3: true, 5: true, 6: true}
+
+	tests := []struct {
+		name string
+		seed int64
+	}{
+		{"seed 1", 1},
+		{"seed 42", 42},
+		{"seed 2024", 2024},
+		{"negative seed", -7},
+	}
+	orders := make(map[string]bool)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := newTestRun(students).priorityOrder(rand.New(rand.NewSource(tt.seed)))
+			if len(order) != len(students) {
+				t.Fatalf("got %d students, want %d", len(order), len(students))
+			}
+			if order[0].ID != 2 || order[len(order)-1].ID != 4 {
+				t.Errorf("got first %d and last %d, want 2 and 4", order[0].ID, order[len(order)-1].ID)
+			}
+
+			want := lotteryOrder(tt.seed, ids, ties)
+			var got []uint
+			for _, student := range order[1 : len(order)-1] {
+				got = append(got, student.ID)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("got tie order %v, want lottery order %v", got, want)
+				}
+			}
+
+			again := newTestRun(students).priorityOrder(rand.New(rand.NewSource(tt.seed)))
+			for i := range order {
+				if order[i].ID != again[i].ID {
+					t.Fatalf("seed %d gave different orders", tt.seed)
+				}
+			}
+
+			orders[fmt.Sprint(got)] = true
+		})
+	}
+	if len(orders) < 2 {
+		t.Errorf("every seed gave the same tie order %v", orders)
+	}
+}
+
+func TestAllocateTieBreak(t *testing.T) {
+	tests := []struct {
+		name   string
+		cgpas  [3]float64
+		seed   int64
+		winner uint
+	}{
+		{"higher cgpa wins", [3]float64{7.0, 9.0, 8.0}, 1, 2},
+		{"higher cgpa wins whatever the seed", [3]float64{7.0, 9.0, 8.0}, 99, 2},
+		{"tie goes to the lottery", [3]float64{8.0, 8.0, 6.0}, 1, 0},
+		{"tie under another seed", [3]float64{8.0, 8.0, 6.0}, 7, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			students := []models.Student{
+				{ID: 1, RegisterNo: "R1", Department: "CSE", CGPA: tt.cgpas[0]},
+				{ID: 2, RegisterNo: "R2", Department: "CSE", CGPA: tt.cgpas[1]},
+				{ID: 3, RegisterNo: "R3", Department: "CSE", CGPA: tt.cgpas[2]},
+			}
+			run := newTestRun(students)
+			run.categories[1] = &models.ElectiveCategory{ID: 1, MaxSelections: 1}
+			run.courses[10] = &models.Course{ID: 10, CourseType: 1, TotalSeats: 1, Departments: models.StringArray{"CSE"}}
+			run.courseOrder = []uint{10}
+			for _, student := range students {
+				run.preferences[student.ID] = []models.CoursePreference{{StudentID: student.ID, CategoryID: 1, CourseID: 10, Rank: 1}}
+			}
+
+			winner := tt.winner
+			if winner == 0 {
+				winner = lotteryOrder(tt.seed, []uint{1, 2, 3}, map[uint]bool{1: true, 2: true})[0]
+			}
+
+			report := run.allocate(rand.New(rand.NewSource(tt.seed)))
+			if len(report.Assignments) != 1 {
+				t.Fatalf("got %d assignments, want 1", len(report.Assignments))
+			}
+			if got := report.Assignments[0].StudentID; got != winner {
+				t.Errorf("got student %d, want %d", got, winner)
+			}
+			if len(report.Unfilled) != 2 {
+				t.Errorf("got %d shortfalls, want 2", len(report.Unfilled))
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package usecase
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -13,15 +17,41 @@ import (
 )
 
 type authService struct {
-	studentRepo domain.StudentRepository
-	jwtConfig   config.JWTConfig
+	studentRepo      domain.StudentRepository
+	rosterRepo       domain.RosterRepository
+	refreshTokenRepo domain.RefreshTokenRepository
+	loginAttemptRepo domain.LoginAttemptRepository
+	uow              domain.UnitOfWork
+	notifier         domain.Notifier
+	jwtConfig        config.JWTConfig
+	authConfig       config.AuthConfig
+	keys             *keyring
+	passwordPolicy   *passwordPolicy
 }
 
-func NewAuthService(studentRepo domain.StudentRepository, jwtConfig config.JWTConfig) domain.AuthService {
-	return &authService{
-		studentRepo: studentRepo,
-		jwtConfig:   jwtConfig,
+func NewAuthService(studentRepo domain.StudentRepository, rosterRepo domain.RosterRepository, refreshTokenRepo domain.RefreshTokenRepository, loginAttemptRepo domain.LoginAttemptRepository, uow domain.UnitOfWork, notifier domain.Notifier, jwtConfig config.JWTConfig, authConfig config.AuthConfig) (domain.AuthService, error) {
+	keys, err := newKeyring(jwtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := newPasswordPolicy(authConfig.Password)
+	if err != nil {
+		return nil, err
 	}
+
+	return &authService{
+		studentRepo:      studentRepo,
+		rosterRepo:       rosterRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		uow:              uow,
+		notifier:         notifier,
+		jwtConfig:        jwtConfig,
+		authConfig:       authConfig,
+		keys:             keys,
+		passwordPolicy:   policy,
+	}, nil
 }
 
 type Claims struct {
@@ -29,14 +59,34 @@ type Claims struct {
 	RegisterNo string `json:"register_no"`
 	Department string `json:"department"`
 	Name       string `json:"name"`
+	Role       string `json:"role"`
 	jwt.RegisteredClaims
 }
 
-func (s *authService) Register(registerNo, password, department, name string) (*models.Student, error) {
+// Register creates an account for a student on the roster. Department and
+// batch always come from the roster. Bootstrap admins, listed in the auth
+// config, may register without a roster entry and get the admin role.
+func (s *authService) Register(registerNo, password, name string) (*models.Student, error) {
 	// Check if student already exists
 	existingStudent, err := s.studentRepo.GetByRegisterNo(registerNo)
 	if err == nil && existingStudent != nil {
-		return nil, errors.New("student already exists")
+		return nil, domain.ErrStudentExists
+	}
+
+	role := models.RoleStudent
+	if s.isBootstrapAdmin(registerNo) {
+		role = models.RoleAdmin
+	}
+
+	entry, err := s.rosterRepo.GetByRegisterNo(registerNo)
+	if errors.Is(err, gorm.ErrRecordNotFound) && role == models.RoleAdmin {
+		entry = &models.RosterEntry{RegisterNo: registerNo}
+	} else if err != nil {
+		return nil, notFoundAs(err, domain.ErrNotOnRoster)
+	}
+
+	if err := s.passwordPolicy.check("password", password, registerNo); err != nil {
+		return nil, err
 	}
 
 	// Hash password
@@ -49,8 +99,12 @@ func (s *authService) Register(registerNo, password, department, name string) (*
 	student := &models.Student{
 		RegisterNo: registerNo,
 		Password:   string(hashedPassword),
-		Department: department,
+		Department: entry.Department,
+		Batch:      entry.Batch,
+		Semester:   entry.Semester,
+		CGPA:       entry.CGPA,
 		Name:       name,
+		Role:       role,
 	}
 
 	err = s.studentRepo.Create(student)
@@ -61,59 +115,237 @@ func (s *authService) Register(registerNo, password, department, name string) (*
 	return student, nil
 }
 
-func (s *authService) Login(registerNo, password string) (string, *models.Student, error) {
+func (s *authService) Login(registerNo, password, ip string) (*models.TokenPair, *models.Student, error) {
+	if err := s.checkLoginAllowed(registerNo, ip); err != nil {
+		return nil, nil, err
+	}
+
 	student, err := s.studentRepo.GetByRegisterNo(registerNo)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", nil, errors.New("invalid credentials")
+			s.recordLoginFailure(registerNo, ip)
+			return nil, nil, domain.ErrInvalidCredentials
 		}
-		return "", nil, err
+		return nil, nil, err
 	}
 
 	// Verify password
 	err = bcrypt.CompareHashAndPassword([]byte(student.Password), []byte(password))
 	if err != nil {
-		return "", nil, errors.New("invalid credentials")
+		s.recordLoginFailure(registerNo, ip)
+		return nil, nil, domain.ErrInvalidCredentials
+	}
+
+	s.resetLoginFailures(registerNo)
+
+	familyID, err := newOpaqueToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.issueTokens(s.refreshTokenRepo, student, familyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, student, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is revoked; presenting it again revokes every token of its family, since
+// that means it was copied.
+func (s *authService) Refresh(refreshToken string) (*models.TokenPair, error) {
+	var tokens *models.TokenPair
+	var reused bool
+	err := s.uow.Do(func(repos *domain.Repositories) error {
+		stored, err := repos.RefreshTokens.GetByHashForUpdate(hashToken(refreshToken))
+		if err != nil {
+			return notFoundAs(err, domain.ErrInvalidRefreshToken)
+		}
+
+		if stored.RevokedAt != nil {
+			reused = true
+			return repos.RefreshTokens.RevokeFamily(stored.FamilyID)
+		}
+
+		if time.Now().After(stored.ExpiresAt) {
+			return domain.ErrInvalidRefreshToken
+		}
+
+		student, err := repos.Students.GetByID(stored.StudentID)
+		if err != nil {
+			return notFoundAs(err, domain.ErrInvalidRefreshToken)
+		}
+
+		if err := repos.RefreshTokens.Revoke(stored.ID); err != nil {
+			return err
+		}
+
+		tokens, err = s.issueTokens(repos.RefreshTokens, student, stored.FamilyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, domain.ErrRefreshTokenReused
+	}
+	return tokens, nil
+}
+
+// Logout revokes the refresh token and every token rotated from the same
+// login.
+func (s *authService) Logout(refreshToken string) error {
+	stored, err := s.refreshTokenRepo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrInvalidRefreshToken
+		}
+		return err
 	}
 
+	return s.refreshTokenRepo.RevokeFamily(stored.FamilyID)
+}
+
+// issueTokens signs an access token for student and stores a new refresh
+// token in familyID through refreshTokens.
+func (s *authService) issueTokens(refreshTokens domain.RefreshTokenRepository, student *models.Student, familyID string) (*models.TokenPair, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.jwtConfig.AccessTokenTTL)
+
 	// Generate JWT token
 	claims := &Claims{
 		StudentID:  student.ID,
 		RegisterNo: student.RegisterNo,
 		Department: student.Department,
 		Name:       student.Name,
+		Role:       student.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			IssuedAt: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.jwtConfig.Secret))
+	tokenString, err := s.keys.sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = refreshTokens.Create(&models.RefreshToken{
+		StudentID: student.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(s.jwtConfig.RefreshTokenTTL),
+	})
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
-	return tokenString, student, nil
+	return &models.TokenPair{
+		AccessToken:  tokenString,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+	}, nil
 }
 
 func (s *authService) ValidateToken(tokenString string) (*models.Student, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtConfig.Secret), nil
-	})
+	token, err := s.keys.parse(tokenString, claims)
 
 	if err != nil {
-		return nil, err
+		return nil, domain.ErrUnauthorized
 	}
 
 	if !token.Valid {
-		return nil, errors.New("invalid token")
+		return nil, domain.ErrUnauthorized
 	}
 
 	student, err := s.studentRepo.GetByID(claims.StudentID)
 	if err != nil {
+		return nil, notFoundAs(err, domain.ErrUnauthorized)
+	}
+
+	// Tokens issued before a password change belong to ended sessions.
+	// IssuedAt has second precision, so compare at that precision.
+	if student.PasswordChangedAt != nil && claims.IssuedAt != nil &&
+		claims.IssuedAt.Time.Before(student.PasswordChangedAt.Truncate(time.Second)) {
+		return nil, domain.ErrUnauthorized.WithMessage("token was issued before the last password change")
+	}
+
+	return student, nil
+}
+
+// JWKS returns the public keys other services can verify our tokens with.
+func (s *authService) JWKS() []models.JSONWebKey {
+	return s.keys.jwks()
+}
+
+func (s *authService) SetRole(registerNo, role string) (*models.Student, error) {
+	if !models.IsValidRole(role) {
+		return nil, domain.ErrInvalidRole
+	}
+
+	student, err := s.studentRepo.GetByRegisterNo(registerNo)
+	if err != nil {
+		return nil, notFoundAs(err, domain.ErrStudentNotFound)
+	}
+
+	student.Role = role
+	if err := s.studentRepo.Update(student); err != nil {
 		return nil, err
 	}
 
 	return student, nil
 }
+
+// BootstrapAdmins promotes the existing accounts of the configured bootstrap
+// admins to admin. Register numbers without an account are left for
+// Register.
+func (s *authService) BootstrapAdmins() error {
+	for _, registerNo := range s.authConfig.AdminRegisterNos {
+		student, err := s.studentRepo.GetByRegisterNo(registerNo)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if student.Role == models.RoleAdmin {
+			continue
+		}
+
+		student.Role = models.RoleAdmin
+		if err := s.studentRepo.Update(student); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *authService) isBootstrapAdmin(registerNo string) bool {
+	for _, adminRegisterNo := range s.authConfig.AdminRegisterNos {
+		if adminRegisterNo == registerNo {
+			return true
+		}
+	}
+	return false
+}
+
+// newOpaqueToken returns 32 random bytes encoded for use in URLs and JSON.
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
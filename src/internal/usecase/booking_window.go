@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+// checkBookingWindow checks that student may book courses of the term, or
+// submit preferences for it, at now. Term 0 has no window.
+func checkBookingWindow(repos *domain.Repositories, student *models.Student, termID uint, now time.Time) error {
+	if termID == 0 {
+		return nil
+	}
+
+	schedule, err := loadSchedule(repos.Windows, repos.Phases, student, termID)
+	if err != nil {
+		return err
+	}
+
+	if schedule == nil {
+		return domain.ErrBookingWindowNotOpen.WithMessage("no booking window has been scheduled for you in this term")
+	}
+	if now.Before(schedule.OpensAt) {
+		if schedule.Phase != "" {
+			return domain.ErrBookingWindowNotOpen.WithMessage(fmt.Sprintf("booking opens for %s at %s", schedule.Phase, schedule.OpensAt.Format(time.RFC3339)))
+		}
+		return domain.ErrBookingWindowNotOpen.WithMessage(fmt.Sprintf("booking opens at %s", schedule.OpensAt.Format(time.RFC3339)))
+	}
+	if !now.Before(schedule.ClosesAt) {
+		return domain.ErrBookingWindowClosed.WithMessage(fmt.Sprintf("booking closed at %s", schedule.ClosesAt.Format(time.RFC3339)))
+	}
+	return nil
+}
+
+// loadSchedule returns the student's booking schedule for the term, or nil if
+// they have none.
+func loadSchedule(windowRepo domain.BookingWindowRepository, phaseRepo domain.BookingPhaseRepository, student *models.Student, termID uint) (*models.BookingSchedule, error) {
+	windows, err := windowRepo.GetByTermID(termID)
+	if err != nil {
+		return nil, err
+	}
+
+	phases, err := phaseRepo.GetByTermID(termID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheduleFor(windows, phases, student), nil
+}
+
+// scheduleFor combines the student's booking window with their booking phase,
+// which can only delay the opening. It returns nil if no window applies, or if
+// the term has phases and none applies.
+func scheduleFor(windows []models.BookingWindow, phases []models.BookingPhase, student *models.Student) *models.BookingSchedule {
+	window := windowFor(windows, student)
+	if window == nil {
+		return nil
+	}
+
+	schedule := &models.BookingSchedule{
+		OpensAt:  window.OpensAt,
+		ClosesAt: window.ClosesAt,
+	}
+	if len(phases) == 0 {
+		return schedule
+	}
+
+	phase := phaseFor(phases, student)
+	if phase == nil {
+		return nil
+	}
+	schedule.Phase = phase.Name
+	if phase.StartsAt.After(schedule.OpensAt) {
+		schedule.OpensAt = phase.StartsAt
+	}
+	return schedule
+}
+
+// windowFor picks the most specific window matching student, preferring the
+// earliest opening among equally specific ones. windows must be sorted by
+// opening time.
+func windowFor(windows []models.BookingWindow, student *models.Student) *models.BookingWindow {
+	var best *models.BookingWindow
+	for i := range windows {
+		window := &windows[i]
+		if !window.Matches(student) {
+			continue
+		}
+		if best == nil || window.Specificity() > best.Specificity() {
+			best = window
+		}
+	}
+	return best
+}
+
+// phaseFor picks the earliest phase matching student. phases must be sorted
+// by start time.
+func phaseFor(phases []models.BookingPhase, student *models.Student) *models.BookingPhase {
+	for i := range phases {
+		if phases[i].Matches(student) {
+			return &phases[i]
+		}
+	}
+	return nil
+}
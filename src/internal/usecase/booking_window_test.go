@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+func TestScheduleFor(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 7, d, 9, 0, 0, 0, time.UTC) }
+	student := &models.Student{Department: "CSE", Batch: 2023, CGPA: 8.2}
+
+	// Windows are sorted by opening time, as the repository returns them
+	everyone := models.BookingWindow{OpensAt: day(1), ClosesAt: day(20)}
+	batch := models.BookingWindow{Batch: 2023, OpensAt: day(2), ClosesAt: day(20)}
+	department := models.BookingWindow{Department: "CSE", OpensAt: day(3), ClosesAt: day(20)}
+	both := models.BookingWindow{Department: "CSE", Batch: 2023, OpensAt: day(4), ClosesAt: day(18)}
+	otherDepartment := models.BookingWindow{Department: "ECE", OpensAt: day(1), ClosesAt: day(20)}
+	earlyBatch := models.BookingWindow{Batch: 2023, OpensAt: day(1), ClosesAt: day(10)}
+
+	toppers := models.BookingPhase{Name: "toppers", MinCGPA: 9, StartsAt: day(2)}
+	seniors := models.BookingPhase{Name: "seniors", Batch: 2023, StartsAt: day(5)}
+	rest := models.BookingPhase{Name: "rest", StartsAt: day(7)}
+	early := models.BookingPhase{Name: "early", StartsAt: day(1)}
+	juniors := models.BookingPhase{Name: "juniors", Batch: 2024, StartsAt: day(3)}
+
+	tests := []struct {
+		name    string
+		windows []models.BookingWindow
+		phases  []models.BookingPhase
+		want    *models.BookingSchedule
+	}{
+		{
+			name: "no windows",
+		},
+		{
+			name:    "no matching window",
+			windows: []models.BookingWindow{otherDepartment},
+		},
+		{
+			name:    "window for everyone",
+			windows: []models.BookingWindow{everyone, otherDepartment},
+			want:    &models.BookingSchedule{OpensAt: day(1), ClosesAt: day(20)},
+		},
+		{
+			name:    "batch beats everyone",
+			windows: []models.BookingWindow{everyone, batch},
+			want:    &models.BookingSchedule{OpensAt: day(2), ClosesAt: day(20)},
+		},
+		{
+			name:    "department beats batch",
+			windows: []models.BookingWindow{everyone, batch, department},
+			want:    &models.BookingSchedule{OpensAt: day(3), ClosesAt: day(20)},
+		},
+		{
+			name:    "department and batch beats department",
+			windows: []models.BookingWindow{everyone, batch, department, both},
+			want:    &models.BookingSchedule{OpensAt: day(4), ClosesAt: day(18)},
+		},
+		{
+			name:    "earliest of equally specific windows",
+			windows: []models.BookingWindow{earlyBatch, batch},
+			want:    &models.BookingSchedule{OpensAt: day(1), ClosesAt: day(10)},
+		},
+		{
+			name:    "earliest matching phase",
+			windows: []models.BookingWindow{everyone},
+			phases:  []models.BookingPhase{toppers, seniors, rest},
+			want:    &models.BookingSchedule{OpensAt: day(5), ClosesAt: day(20), Phase: "seniors"},
+		},
+		{
+			name:    "phase cannot open before the window",
+			windows: []models.BookingWindow{department},
+			phases:  []models.BookingPhase{early},
+			want:    &models.BookingSchedule{OpensAt: day(3), ClosesAt: day(20), Phase: "early"},
+		},
+		{
+			name:    "term with phases but none matching",
+			windows: []models.BookingWindow{everyone},
+			phases:  []models.BookingPhase{toppers, juniors},
+		},
+		{
+			name:   "phase without a window",
+			phases: []models.BookingPhase{rest},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scheduleFor(tt.windows, tt.phases, student)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("got %+v, want no schedule", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("got no schedule, want %+v", tt.want)
+			}
+			if !got.OpensAt.Equal(tt.want.OpensAt) || !got.ClosesAt.Equal(tt.want.ClosesAt) || got.Phase != tt.want.Phase {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type electiveCategoryService struct {
+	categoryRepo domain.ElectiveCategoryRepository
+	uow          domain.UnitOfWork
+}
+
+func NewElectiveCategoryService(categoryRepo domain.ElectiveCategoryRepository, uow domain.UnitOfWork) domain.ElectiveCategoryService {
+	return &electiveCategoryService{
+		categoryRepo: categoryRepo,
+		uow:          uow,
+	}
+}
+
+func (s *electiveCategoryService) GetCategories() ([]models.ElectiveCategory, error) {
+	return s.categoryRepo.GetAll()
+}
+
+func (s *electiveCategoryService) CreateCategory(category *models.ElectiveCategory) error {
+	if category.Semesters == nil {
+		category.Semesters = models.IntArray{}
+	}
+
+	return s.uow.Do(func(repos *domain.Repositories) error {
+		if err := checkCategoryName(repos, category.Name, 0); err != nil {
+			return err
+		}
+		return repos.Categories.Create(category)
+	})
+}
+
+// UpdateCategory applies a partial update. Lowering MaxSelections does not
+// cancel bookings students already hold; it only blocks further ones.
+func (s *electiveCategoryService) UpdateCategory(categoryID uint, update *models.ElectiveCategoryUpdate) (*models.ElectiveCategory, error) {
+	var category *models.ElectiveCategory
+	err := s.uow.Do(func(repos *domain.Repositories) error {
+		var err error
+		category, err = repos.Categories.GetByID(categoryID)
+		if err != nil {
+			return notFoundAs(err, domain.ErrCategoryNotFound)
+		}
+
+		if update.Name != nil {
+			if err := checkCategoryName(repos, *update.Name, category.ID); err != nil {
+				return err
+			}
+			category.Name = *update.Name
+		}
+		if update.MaxSelections != nil {
+			category.MaxSelections = *update.MaxSelections
+		}
+		if update.Semesters != nil {
+			category.Semesters = *update.Semesters
+		}
+
+		return repos.Categories.Update(category)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory removes a category that no course belongs to.
+func (s *electiveCategoryService) DeleteCategory(categoryID uint) error {
+	return s.uow.Do(func(repos *domain.Repositories) error {
+		count, err := repos.Courses.CountByType(int(categoryID))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return domain.ErrCategoryInUse
+		}
+
+		return notFoundAs(repos.Categories.Delete(categoryID), domain.ErrCategoryNotFound)
+	})
+}
+
+// checkCategoryName rejects a name already used by a category other than
+// selfID.
+func checkCategoryName(repos *domain.Repositories, name string, selfID uint) error {
+	existing, err := repos.Categories.GetByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != selfID {
+		return domain.ErrCategoryExists
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+// loadEligibility gathers the student's standing for the course eligibility
+// rules. A booking counts as completed once its term has ended.
+func loadEligibility(bookingRepo domain.CourseBookingRepository, termRepo domain.AcademicTermRepository, student *models.Student, now time.Time) (*models.Eligibility, error) {
+	bookings, err := bookingRepo.GetByStudentID(student.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ended, err := endedTerms(termRepo, now)
+	if err != nil {
+		return nil, err
+	}
+
+	eligibility := models.NewEligibility(student)
+	for i := range bookings {
+		eligibility.AddBooking(&bookings[i].Course, ended[bookings[i].TermID])
+	}
+	return eligibility, nil
+}
+
+func endedTerms(termRepo domain.AcademicTermRepository, now time.Time) (map[uint]bool, error) {
+	terms, err := termRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ended := make(map[uint]bool, len(terms))
+	for _, term := range terms {
+		if term.EndsAt.Before(now) {
+			ended[term.ID] = true
+		}
+	}
+	return ended, nil
+}
+
+// checkCourseRules checks the eligibility rules of course and explains the
+// first one the student fails.
+func checkCourseRules(courseRepo domain.CourseRepository, eligibility *models.Eligibility, course *models.Course) error {
+	if eligibility.Semester < course.MinSemester {
+		if eligibility.Semester == 0 {
+			return domain.ErrSemesterTooLow.WithMessage(fmt.Sprintf("%s is open from semester %d and your semester is not on record", course.Name, course.MinSemester))
+		}
+		return domain.ErrSemesterTooLow.WithMessage(fmt.Sprintf("%s is open from semester %d, you are in semester %d", course.Name, course.MinSemester, eligibility.Semester))
+	}
+
+	if eligibility.CGPA < course.MinCGPA {
+		return domain.ErrCGPATooLow.WithMessage(fmt.Sprintf("%s requires a CGPA of at least %.2f, yours is %.2f", course.Name, course.MinCGPA, eligibility.CGPA))
+	}
+
+	if missing := eligibility.MissingPrerequisites(course); len(missing) > 0 {
+		names, err := courseNames(courseRepo, missing)
+		if err != nil {
+			return err
+		}
+		return domain.ErrPrerequisiteMissing.WithMessage(fmt.Sprintf("%s requires %s to be completed first", course.Name, names))
+	}
+
+	if conflicts := eligibility.Conflicts(course); len(conflicts) > 0 {
+		names, err := courseNames(courseRepo, conflicts)
+		if err != nil {
+			return err
+		}
+		return domain.ErrExcludedCourseBooked.WithMessage(fmt.Sprintf("%s cannot be taken together with %s, which you have booked", course.Name, names))
+	}
+
+	return nil
+}
+
+// courseNames lists the names of the courses for error messages.
+func courseNames(courseRepo domain.CourseRepository, ids []uint) (string, error) {
+	courses, err := courseRepo.GetByIDs(ids)
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(ids))
+	for _, course := range courses {
+		names = append(names, course.Name)
+	}
+	if len(names) == 0 {
+		return "an earlier course", nil
+	}
+	return strings.Join(names, ", "), nil
+}
+
+func checkRuleLimits(verr *domain.ValidationError, course *models.Course) {
+	if course.MinSemester < 0 {
+		verr.Add("min_semester", "must be at least 0")
+	}
+	if course.MinCGPA < 0 || course.MinCGPA > 10 {
+		verr.Add("min_cgpa", "must be between 0 and 10")
+	}
+}
+
+// checkRuleReferences checks that the prerequisites and excluded courses of
+// course name other existing courses.
+func checkRuleReferences(verr *domain.ValidationError, courseRepo domain.CourseRepository, course *models.Course) error {
+	fields := []struct {
+		name string
+		ids  models.IntArray
+	}{
+		{"prerequisites", course.Prerequisites},
+		{"excluded_courses", course.ExcludedCourses},
+	}
+
+	for _, field := range fields {
+		if len(field.ids) == 0 {
+			continue
+		}
+
+		ids := make([]uint, 0, len(field.ids))
+		for _, id := range field.ids {
+			ids = append(ids, uint(id))
+		}
+		courses, err := courseRepo.GetByIDs(ids)
+		if err != nil {
+			return err
+		}
+		found := make(map[uint]bool, len(courses))
+		for _, c := range courses {
+			found[c.ID] = true
+		}
+
+		for i, id := range ids {
+			switch {
+			case course.ID != 0 && id == course.ID:
+				verr.Add(fmt.Sprintf("%s[%d]", field.name, i), "must not be the course itself")
+			case !found[id]:
+				verr.Add(fmt.Sprintf("%s[%d]", field.name, i), "must be the id of a course")
+			}
+		}
+	}
+	return nil
+}
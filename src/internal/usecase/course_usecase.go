@@ -3,105 +3,606 @@ package usecase
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/sk/elective/src/internal/config"
 	"github.com/sk/elective/src/internal/domain"
 	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
 )
 
 type courseService struct {
-	courseRepo  domain.CourseRepository
-	bookingRepo domain.CourseBookingRepository
+	categoryRepo domain.ElectiveCategoryRepository
+	termRepo     domain.AcademicTermRepository
+	windowRepo   domain.BookingWindowRepository
+	phaseRepo    domain.BookingPhaseRepository
+	courseRepo   domain.CourseRepository
+	bookingRepo  domain.CourseBookingRepository
+	waitlistRepo domain.WaitlistRepository
+	uow          domain.UnitOfWork
+	assignSeat   seatAssigner
+	bookingCfg   config.BookingConfig
 }
 
-func NewCourseService(courseRepo domain.CourseRepository, bookingRepo domain.CourseBookingRepository) domain.CourseService {
-	return &courseService{
-		courseRepo:  courseRepo,
-		bookingRepo: bookingRepo,
+func NewCourseService(categoryRepo domain.ElectiveCategoryRepository, termRepo domain.AcademicTermRepository, windowRepo domain.BookingWindowRepository, phaseRepo domain.BookingPhaseRepository, courseRepo domain.CourseRepository, bookingRepo domain.CourseBookingRepository, waitlistRepo domain.WaitlistRepository, uow domain.UnitOfWork, bookingConfig config.BookingConfig) (domain.CourseService, error) {
+	assignSeat, err := newSeatAssigner(bookingConfig.SeatStrategy)
+	if err != nil {
+		return nil, err
 	}
+
+	return &courseService{
+		categoryRepo: categoryRepo,
+		termRepo:     termRepo,
+		windowRepo:   windowRepo,
+		phaseRepo:    phaseRepo,
+		courseRepo:   courseRepo,
+		bookingRepo:  bookingRepo,
+		waitlistRepo: waitlistRepo,
+		uow:          uow,
+		assignSeat:   assignSeat,
+		bookingCfg:   bookingConfig,
+	}, nil
 }
-func (s *courseService) GetAllCourses() ([]models.Course, error) {
-	return s.courseRepo.GetAll()
+
+func (s *courseService) GetAllCourses(query models.CourseQuery) (*models.CoursePage, error) {
+	return s.findCourses(query)
 }
-func (s *courseService) GetAvailableCourses(studentID uint, department string) ([]models.Course, error) {
-	var availableCourses []models.Course
 
-	// Get type 1 courses
-	type1Courses, err := s.courseRepo.GetByDepartmentAndType(department, 1)
+// GetAvailableCourses lists the courses offered to the student's department
+// in categories open to their semester where they still have selections left.
+// Courses the student already booked are left out. Without a term filter the
+// active term is listed. The page carries the student's booking schedule for
+// the term so clients can show when booking opens.
+func (s *courseService) GetAvailableCourses(student *models.Student, query models.CourseQuery) (*models.CoursePage, error) {
+	categories, err := s.categoryRepo.GetAll()
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if student has already booked a type 1 course
-	type1Count, err := s.bookingRepo.CountByStudentAndType(studentID, 1)
+	if query.TermID == nil {
+		termID, err := s.activeTermID()
+		if err != nil {
+			return nil, err
+		}
+		query.TermID = &termID
+	}
+
+	query.Department = student.Department
+	query.ExcludeBookedBy = student.ID
+	query.CourseTypes = []int{}
+
+	query.EligibleFor, err = loadEligibility(s.bookingRepo, s.termRepo, student, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range categories {
+		category := &categories[i]
+		if query.CourseType != 0 && query.CourseType != int(category.ID) {
+			continue
+		}
+
+		count, err := s.bookingRepo.CountByStudentAndType(student.ID, *query.TermID, int(category.ID))
+		if err != nil {
+			return nil, err
+		}
+		if checkCategory(category, student, count) == nil {
+			query.CourseTypes = append(query.CourseTypes, int(category.ID))
+		}
+	}
+
+	page := &models.CoursePage{Courses: []models.Course{}, Page: query.Page, Limit: query.Limit}
+	if len(query.CourseTypes) > 0 {
+		page, err = s.findCourses(query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if *query.TermID != 0 {
+		page.Schedule, err = loadSchedule(s.windowRepo, s.phaseRepo, student, *query.TermID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// activeTermID returns the term students are booking for, or 0 if no term is
+// active.
+func (s *courseService) activeTermID() (uint, error) {
+	term, err := s.termRepo.GetActive()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return term.ID, nil
+}
+
+func (s *courseService) findCourses(query models.CourseQuery) (*models.CoursePage, error) {
+	courses, total, err := s.courseRepo.Find(query)
 	if err != nil {
 		return nil, err
 	}
 
-	if type1Count == 0 {
-		availableCourses = append(availableCourses, type1Courses...)
+	return &models.CoursePage{
+		Courses: courses,
+		Total:   total,
+		Page:    query.Page,
+		Limit:   query.Limit,
+	}, nil
+}
+
+// GetCourseDetail returns the course with its enrollment stats and whether the
+// student could book it.
+func (s *courseService) GetCourseDetail(student *models.Student, courseID uint) (*models.CourseDetail, error) {
+	course, err := s.courseRepo.GetByID(courseID)
+	if err != nil {
+		return nil, notFoundAs(err, domain.ErrCourseNotFound)
+	}
+
+	byDept, err := s.bookingRepo.CountByDepartment(courseID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get type 2 courses
-	type2Courses, err := s.courseRepo.GetByDepartmentAndType(department, 2)
+	waitlistLength, err := s.waitlistRepo.CountByCourseID(courseID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if student has already booked a type 2 course
-	type2Count, err := s.bookingRepo.CountByStudentAndType(studentID, 2)
+	booked, err := s.bookingRepo.CountByStudentAndCourse(student.ID, courseID)
 	if err != nil {
 		return nil, err
 	}
 
-	if type2Count == 0 {
-		availableCourses = append(availableCourses, type2Courses...)
+	detail := &models.CourseDetail{
+		Course:         *course,
+		BookingsByDept: byDept,
+		WaitlistLength: waitlistLength,
+		Booked:         booked > 0,
+		Quotas:         quotaUsage(course, byDept),
+		QuotaReleased:  len(course.DepartmentQuotas) > 0 && !course.QuotasActive(time.Now()),
+	}
+
+	detail.IneligibleReason, err = s.ineligibleReason(student, course)
+	if err != nil {
+		return nil, err
 	}
+	detail.Eligible = detail.IneligibleReason == ""
 
-	return availableCourses, nil
+	return detail, nil
 }
 
-func (s *courseService) BookCourse(studentID uint, courseID uint, seatNo string) error {
-	course, err := s.courseRepo.GetByID(courseID)
+// quotaUsage lists the course's department quotas in department order.
+func quotaUsage(course *models.Course, byDept map[string]int64) []models.DepartmentQuotaUsage {
+	usage := make([]models.DepartmentQuotaUsage, 0, len(course.DepartmentQuotas))
+	for department, quota := range course.DepartmentQuotas {
+		usage = append(usage, models.DepartmentQuotaUsage{
+			Department: department,
+			Quota:      quota,
+			Booked:     byDept[department],
+		})
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Department < usage[j].Department })
+	return usage
+}
+
+// ineligibleReason explains why the student may not book the course, or
+// returns an empty string if they may. A full course does not make the student
+// ineligible since they can still join the waitlist.
+func (s *courseService) ineligibleReason(student *models.Student, course *models.Course) (string, error) {
+	if !course.HasDepartment(student.Department) {
+		return domain.ErrCourseNotOffered.Error(), nil
+	}
+
+	category, err := s.categoryRepo.GetByID(uint(course.CourseType))
 	if err != nil {
-		return err
+		return "", notFoundAs(err, domain.ErrCategoryNotFound)
+	}
+
+	count, err := s.bookingRepo.CountByStudentAndType(student.ID, course.TermID, course.CourseType)
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkCategory(category, student, count); err != nil {
+		return err.Error(), nil
 	}
 
-	// Check if student has already booked a course of this type
-	count, err := s.bookingRepo.CountByStudentAndType(studentID, course.CourseType)
+	eligibility, err := loadEligibility(s.bookingRepo, s.termRepo, student, time.Now())
 	if err != nil {
+		return "", err
+	}
+	err = checkCourseRules(s.courseRepo, eligibility, course)
+	if err == nil {
+		_, err = pickSection(s.bookingRepo, student.ID, course, "")
+	}
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Error(), nil
+	}
+	return "", err
+}
+
+// checkCategory checks that the student may pick one more course in category
+// given the number of its courses they have booked.
+func checkCategory(category *models.ElectiveCategory, student *models.Student, booked int64) *domain.Error {
+	if !category.AllowsSemester(student.Semester) {
+		return domain.ErrSemesterIneligible.WithMessage(fmt.Sprintf("%s is not open to semester %d students", category.Name, student.Semester))
+	}
+	if booked >= int64(category.MaxSelections) {
+		return domain.ErrTypeQuotaExceeded.WithMessage(fmt.Sprintf("you have already booked %d %s course(s), the maximum allowed", booked, category.Name))
+	}
+	return nil
+}
+
+func (s *courseService) BookCourse(studentID uint, courseID uint, seatNo string, section string) (*models.CourseBooking, error) {
+	if err := s.releaseQuota(courseID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var booking *models.CourseBooking
+	err := s.uow.Do(func(repos *domain.Repositories) error {
+		var err error
+		booking, err = s.bookCourse(repos, studentID, courseID, seatNo, section)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return booking, nil
+}
 
-	if count > 0 {
-		return errors.New(fmt.Sprintf("you have already booked a type %d course", course.CourseType))
+// bookCourse books a seat using repos, which must be bound to a transaction,
+// and returns the booking. An empty seatNo lets the configured strategy pick
+// one, and an empty section picks the first that fits the student's timetable. The student row is locked to serialise a student's concurrent
+// bookings and the course row is locked so that seat checks and updates
+// cannot interleave. The student's booking window for the course's term must
+// be open, and the term must not be waiting for a preference allocation.
+func (s *courseService) bookCourse(repos *domain.Repositories, studentID uint, courseID uint, seatNo string, section string) (*models.CourseBooking, error) {
+	student, err := repos.Students.GetByIDForUpdate(studentID)
+	if err != nil {
+		return nil, notFoundAs(err, domain.ErrStudentNotFound)
+	}
+
+	course, err := repos.Courses.GetByIDForUpdate(courseID)
+	if err != nil {
+		return nil, notFoundAs(err, domain.ErrCourseNotFound)
+	}
+
+	if err := checkNotAllocating(repos, course.TermID); err != nil {
+		return nil, err
+	}
+
+	if err := checkBookingWindow(repos, student, course.TermID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return placeBooking(repos, s.assignSeat, student, course, seatNo, section)
+}
+
+// checkNotAllocating checks that the term does not wait for a preference
+// allocation, which alone hands out its seats. Term 0 never does.
+func checkNotAllocating(repos *domain.Repositories, termID uint) error {
+	if termID == 0 {
+		return nil
+	}
+	term, err := repos.Terms.GetByID(termID)
+	if err != nil {
+		return notFoundAs(err, domain.ErrTermNotFound)
+	}
+	if term.UsesPreferences() {
+		return domain.ErrPreferenceAllocation
+	}
+	return nil
+}
+
+// placeBooking books a seat in course for student, using assignSeat when no
+// seat is given, in the requested section or else the first that fits the
+// student's timetable. Both rows must already be locked by the caller's
+// transaction.
+func placeBooking(repos *domain.Repositories, assignSeat seatAssigner, student *models.Student, course *models.Course, seatNo string, section string) (*models.CourseBooking, error) {
+	if err := checkEligible(repos, student, course); err != nil {
+		return nil, err
+	}
+
+	if course.IsFull() {
+		return nil, domain.ErrCourseFull
+	}
+
+	if err := checkDepartmentQuota(repos, student, course, time.Now()); err != nil {
+		return nil, err
+	}
+
+	section, err := pickSection(repos.Bookings, student.ID, course, section)
+	if err != nil {
+		return nil, err
+	}
+
+	if seatNo == "" {
+		seatNo, err = assignSeat(course, student)
+	} else {
+		seatNo, err = normalizeSeat(course, seatNo)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	// Check if seat is already booked
-	for _, bookedSeat := range course.SeatsBooked {
-		if bookedSeat == seatNo {
-			return errors.New("seat already booked")
-		}
+	if course.IsSeatBooked(seatNo) {
+		return nil, domain.ErrSeatTaken
 	}
 
 	// Create booking
 	booking := &models.CourseBooking{
-		StudentID: studentID,
-		CourseID:  courseID,
+		StudentID: student.ID,
+		CourseID:  course.ID,
+		TermID:    course.TermID,
 		SeatNo:    seatNo,
+		Section:   section,
 	}
 
-	err = s.bookingRepo.Create(booking)
+	err = repos.Bookings.Create(booking)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Update course seats
 	course.SeatsBooked = append(course.SeatsBooked, seatNo)
-	err = s.courseRepo.Update(course)
+	if err := repos.Courses.Update(course); err != nil {
+		return nil, err
+	}
+
+	// A booked student no longer needs their place in the queue
+	if err := repos.Waitlists.Delete(student.ID, course.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return booking, nil
+}
+
+// releaseQuota promotes the course's waitlist once, when its department
+// quotas have lapsed, so that students queued behind a quota get the seats it
+// frees before anyone who books them directly. It runs in its own transaction
+// ahead of bookings and waitlist joins of the course.
+func (s *courseService) releaseQuota(courseID uint, now time.Time) error {
+	course, err := s.courseRepo.GetByID(courseID)
+	if err != nil {
+		return notFoundAs(err, domain.ErrCourseNotFound)
+	}
+	if !course.QuotaReleaseDue(now) {
+		return nil
+	}
+
+	return s.uow.Do(func(repos *domain.Repositories) error {
+		course, err := repos.Courses.GetByIDForUpdate(courseID)
+		if err != nil {
+			return notFoundAs(err, domain.ErrCourseNotFound)
+		}
+		// Another request may have done it while we waited for the lock
+		if !course.QuotaReleaseDue(now) {
+			return nil
+		}
+
+		if err := s.promoteWaitlist(repos, course); err != nil {
+			return err
+		}
+		course.QuotaReleaseDone = true
+		return repos.Courses.Update(course)
+	})
+}
+
+// checkDepartmentQuota checks that a free seat of course is open to the
+// student's department while the course's quotas are in force.
+func checkDepartmentQuota(repos *domain.Repositories, student *models.Student, course *models.Course, now time.Time) error {
+	if !course.QuotasActive(now) {
+		return nil
+	}
+
+	byDept, err := repos.Bookings.CountByDepartment(course.ID)
 	if err != nil {
 		return err
 	}
+	if !course.HasSeatFor(student.Department, byDept, now) {
+		return domain.ErrDepartmentQuotaFull
+	}
+	return nil
+}
+
+// checkEligible checks that course is offered to the student's department,
+// their category quota and semester for it, that they have not booked it
+// already and that they meet its eligibility rules. Timetable clashes depend
+// on the section and are checked by pickSection.
+func checkEligible(repos *domain.Repositories, student *models.Student, course *models.Course) error {
+	if !course.HasDepartment(student.Department) {
+		return domain.ErrCourseNotOffered
+	}
+
+	category, err := repos.Categories.GetByID(uint(course.CourseType))
+	if err != nil {
+		return notFoundAs(err, domain.ErrCategoryNotFound)
+	}
+
+	booked, err := repos.Bookings.CountByStudentAndCourse(student.ID, course.ID)
+	if err != nil {
+		return err
+	}
+	if booked > 0 {
+		return domain.ErrAlreadyBooked
+	}
 
+	count, err := repos.Bookings.CountByStudentAndType(student.ID, course.TermID, course.CourseType)
+	if err != nil {
+		return err
+	}
+	if err := checkCategory(category, student, count); err != nil {
+		return err
+	}
+
+	eligibility, err := loadEligibility(repos.Bookings, repos.Terms, student, time.Now())
+	if err != nil {
+		return err
+	}
+	return checkCourseRules(repos.Courses, eligibility, course)
+}
+
+// SwapCourse moves the student from one of their bookings in the target
+// course's category to the target course. fromBookingID picks the booking to
+// give up and may be 0 when the student holds only one booking in the
+// category. The old booking is only released if the new seat can be taken;
+// otherwise the whole swap is rolled back.
+func (s *courseService) SwapCourse(studentID uint, fromBookingID uint, courseID uint, seatNo string, section string) (*models.CourseBooking, error) {
+	if err := s.releaseQuota(courseID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var booking *models.CourseBooking
+	err := s.uow.Do(func(repos *domain.Repositories) error {
+		if _, err := repos.Students.GetByIDForUpdate(studentID); err != nil {
+			return notFoundAs(err, domain.ErrStudentNotFound)
+		}
+
+		target, err := repos.Courses.GetByID(courseID)
+		if err != nil {
+			return notFoundAs(err, domain.ErrCourseNotFound)
+		}
+
+		current, err := swapSource(repos, studentID, fromBookingID, target)
+		if err != nil {
+			return err
+		}
+
+		if current.CourseID == target.ID {
+			return domain.ErrAlreadyBooked
+		}
+
+		// Lock both courses in id order so that opposite swaps cannot deadlock
+		first, second := current.CourseID, target.ID
+		if first > second {
+			first, second = second, first
+		}
+		if _, err := repos.Courses.GetByIDForUpdate(first); err != nil {
+			return err
+		}
+		if _, err := repos.Courses.GetByIDForUpdate(second); err != nil {
+			return err
+		}
+
+		if err := s.cancelBooking(repos, studentID, current.ID, false); err != nil {
+			return err
+		}
+
+		booking, err = s.bookCourse(repos, studentID, target.ID, seatNo, section)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// swapSource returns the booking a swap into target gives up, which must be
+// in the same term and category.
+func swapSource(repos *domain.Repositories, studentID uint, fromBookingID uint, target *models.Course) (*models.CourseBooking, error) {
+	bookings, err := repos.Bookings.GetByStudentAndType(studentID, target.TermID, target.CourseType)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromBookingID == 0 {
+		switch len(bookings) {
+		case 0:
+			return nil, domain.ErrNothingToSwap
+		case 1:
+			return &bookings[0], nil
+		default:
+			return nil, domain.ErrAmbiguousSwap
+		}
+	}
+
+	for i := range bookings {
+		if bookings[i].ID == fromBookingID {
+			return &bookings[i], nil
+		}
+	}
+	return nil, domain.ErrNothingToSwap.WithMessage("from_booking_id is not one of your bookings in this course's term and category")
+}
+
+func (s *courseService) CancelBooking(studentID uint, bookingID uint) error {
+	return s.uow.Do(func(repos *domain.Repositories) error {
+		return s.cancelBooking(repos, studentID, bookingID, false)
+	})
+}
+
+// AdminCancelBooking cancels any student's booking and ignores the drop
+// deadline.
+func (s *courseService) AdminCancelBooking(adminID uint, bookingID uint) error {
+	return s.uow.Do(func(repos *domain.Repositories) error {
+		return s.cancelBooking(repos, adminID, bookingID, true)
+	})
+}
+
+// cancelBooking cancels a booking on behalf of actorID and frees its seat.
+// Unless asAdmin is set the booking must belong to actorID and the drop
+// deadline must not have passed.
+func (s *courseService) cancelBooking(repos *domain.Repositories, actorID uint, bookingID uint, asAdmin bool) error {
+	booking, err := repos.Bookings.GetByID(bookingID)
+	if err != nil {
+		return notFoundAs(err, domain.ErrBookingNotFound)
+	}
+
+	if !asAdmin {
+		if booking.StudentID != actorID {
+			return domain.ErrBookingNotFound
+		}
+		if err := s.checkDropDeadline(repos, booking.TermID, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	course, err := repos.Courses.GetByIDForUpdate(booking.CourseID)
+	if err != nil {
+		return notFoundAs(err, domain.ErrCourseNotFound)
+	}
+
+	if err := repos.Bookings.Cancel(booking, actorID); err != nil {
+		return notFoundAs(err, domain.ErrBookingNotFound)
+	}
+
+	course.ReleaseSeat(booking.SeatNo)
+	if err := repos.Courses.Update(course); err != nil {
+		return err
+	}
+
+	return s.promoteWaitlist(repos, course)
+}
+
+// checkDropDeadline checks the drop deadline of the term, or the configured
+// one for bookings outside any term.
+func (s *courseService) checkDropDeadline(repos *domain.Repositories, termID uint, now time.Time) error {
+	var deadline *time.Time
+	if termID == 0 {
+		if !s.bookingCfg.DropDeadline.IsZero() {
+			deadline = &s.bookingCfg.DropDeadline
+		}
+	} else {
+		term, err := repos.Terms.GetByID(termID)
+		if err != nil {
+			return notFoundAs(err, domain.ErrTermNotFound)
+		}
+		deadline = term.DropDeadline
+	}
+
+	if deadline != nil && now.After(*deadline) {
+		return domain.ErrDropDeadlinePassed
+	}
 	return nil
 }
 
@@ -110,14 +611,41 @@ func (s *courseService) GetStudentBookings(studentID uint) ([]models.CourseBooki
 }
 
 func (s *courseService) CreateCourse(course *models.Course) error {
+	verr := domain.NewValidationError()
+
 	// Validate course type
-	if course.CourseType != 1 && course.CourseType != 2 {
-		return errors.New("course type must be 1 or 2")
+	if err := s.checkCategoryExists(course.CourseType); errors.Is(err, domain.ErrCategoryNotFound) {
+		verr.Add("course_type", "must be the id of an elective category")
+	} else if err != nil {
+		return err
+	}
+
+	if course.TermID != 0 {
+		if _, err := s.termRepo.GetByID(course.TermID); errors.Is(err, gorm.ErrRecordNotFound) {
+			verr.Add("term_id", "must be the id of an academic term")
+		} else if err != nil {
+			return err
+		}
 	}
 
 	// Validate required fields
 	if course.Name == "" {
-		return errors.New("course name is required")
+		verr.Add("name", "is required")
+	}
+
+	if course.TotalSeats < 1 {
+		verr.Add("total_seats", "must be at least 1")
+	}
+
+	checkQuotas(verr, course)
+	checkRuleLimits(verr, course)
+	checkSessions(verr, course)
+	if err := checkRuleReferences(verr, s.courseRepo, course); err != nil {
+		return err
+	}
+
+	if err := verr.ErrOrNil(); err != nil {
+		return err
 	}
 
 	// Initialize empty arrays if nil
@@ -133,6 +661,214 @@ func (s *courseService) CreateCourse(course *models.Course) error {
 	if course.Genres == nil {
 		course.Genres = models.StringArray{}
 	}
+	if course.DepartmentQuotas == nil {
+		course.DepartmentQuotas = models.QuotaMap{}
+	}
+	if course.Prerequisites == nil {
+		course.Prerequisites = models.IntArray{}
+	}
+	if course.ExcludedCourses == nil {
+		course.ExcludedCourses = models.IntArray{}
+	}
+	if course.Sessions == nil {
+		course.Sessions = models.SessionList{}
+	}
 
 	return s.courseRepo.Create(course)
 }
+
+// UpdateCourse applies a partial update to a course. Seats can only be removed
+// if they are free and numbered above every booked seat, and the course type is
+// fixed once the course has bookings. Added seats are offered to the waitlist.
+func (s *courseService) UpdateCourse(courseID uint, update *models.CourseUpdate) (*models.Course, error) {
+	var course *models.Course
+	err := s.uow.Do(func(repos *domain.Repositories) error {
+		var err error
+		course, err = repos.Courses.GetByIDForUpdate(courseID)
+		if err != nil {
+			return notFoundAs(err, domain.ErrCourseNotFound)
+		}
+
+		if update.CourseType != nil && *update.CourseType != course.CourseType {
+			if len(course.SeatsBooked) > 0 {
+				return domain.ErrCourseHasBookings
+			}
+			if _, err := repos.Categories.GetByID(uint(*update.CourseType)); err != nil {
+				return notFoundAs(err, domain.ErrCategoryNotFound)
+			}
+		}
+
+		if update.TermID != nil && *update.TermID != course.TermID {
+			if len(course.SeatsBooked) > 0 {
+				return domain.ErrCourseHasBookings.WithMessage("course term cannot be changed once the course has bookings")
+			}
+			if *update.TermID != 0 {
+				if _, err := repos.Terms.GetByID(*update.TermID); err != nil {
+					return notFoundAs(err, domain.ErrTermNotFound)
+				}
+			}
+		}
+
+		grew := false
+		if update.TotalSeats != nil {
+			if err := checkSeatReduction(course, *update.TotalSeats); err != nil {
+				return err
+			}
+			grew = *update.TotalSeats > course.TotalSeats
+			course.TotalSeats = *update.TotalSeats
+		}
+
+		applyCourseUpdate(course, update)
+
+		verr := domain.NewValidationError()
+		checkQuotas(verr, course)
+		checkRuleLimits(verr, course)
+		checkSessions(verr, course)
+		if err := checkRuleReferences(verr, repos.Courses, course); err != nil {
+			return err
+		}
+		if err := verr.ErrOrNil(); err != nil {
+			return err
+		}
+
+		if err := repos.Courses.Update(course); err != nil {
+			return err
+		}
+
+		// Changed quotas may open seats to waitlisted students of other
+		// departments
+		if grew || update.DepartmentQuotas != nil || update.QuotaReleaseAt.Set {
+			return s.promoteWaitlist(repos, course)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return course, nil
+}
+
+// DeleteCourse soft deletes a course and drops its waitlist. Existing bookings
+// are kept as history but no longer count towards the student's type quota.
+func (s *courseService) DeleteCourse(courseID uint) error {
+	return s.uow.Do(func(repos *domain.Repositories) error {
+		if _, err := repos.Courses.GetByIDForUpdate(courseID); err != nil {
+			return notFoundAs(err, domain.ErrCourseNotFound)
+		}
+
+		if err := repos.Waitlists.DeleteByCourseID(courseID); err != nil {
+			return err
+		}
+
+		return notFoundAs(repos.Courses.Delete(courseID), domain.ErrCourseNotFound)
+	})
+}
+
+// checkSeatReduction rejects a new seat total that would leave a booked seat
+// without a place.
+func checkSeatReduction(course *models.Course, totalSeats int) error {
+	if totalSeats >= course.TotalSeats {
+		return nil
+	}
+
+	if totalSeats < len(course.SeatsBooked) {
+		return domain.ErrSeatsBelowBookings.WithMessage(fmt.Sprintf("total seats cannot be reduced below the %d seats already booked", len(course.SeatsBooked)))
+	}
+
+	for _, seat := range course.SeatsBooked {
+		if n, err := strconv.Atoi(seat); err == nil && n > totalSeats {
+			return domain.ErrSeatsBelowBookings.WithMessage(fmt.Sprintf("seat %s is booked, total seats cannot be reduced below %d", seat, n))
+		}
+	}
+
+	return nil
+}
+
+func applyCourseUpdate(course *models.Course, update *models.CourseUpdate) {
+	if update.Name != nil {
+		course.Name = *update.Name
+	}
+	if update.PDFLink != nil {
+		course.PDFLink = *update.PDFLink
+	}
+	if update.Rating != nil {
+		course.Rating = *update.Rating
+	}
+	if update.StaffNames != nil {
+		course.StaffNames = *update.StaffNames
+	}
+	if update.ImageLink != nil {
+		course.ImageLink = *update.ImageLink
+	}
+	if update.Description != nil {
+		course.Description = *update.Description
+	}
+	if update.Departments != nil {
+		course.Departments = *update.Departments
+	}
+	if update.Genres != nil {
+		course.Genres = *update.Genres
+	}
+	if update.CourseType != nil {
+		course.CourseType = *update.CourseType
+	}
+	if update.TermID != nil {
+		course.TermID = *update.TermID
+	}
+	if update.DepartmentQuotas != nil {
+		course.DepartmentQuotas = *update.DepartmentQuotas
+	}
+	update.QuotaReleaseAt.Apply(&course.QuotaReleaseAt)
+	if update.DepartmentQuotas != nil || update.QuotaReleaseAt.Set {
+		course.QuotaReleaseDone = false
+	}
+	if update.Prerequisites != nil {
+		course.Prerequisites = *update.Prerequisites
+	}
+	if update.ExcludedCourses != nil {
+		course.ExcludedCourses = *update.ExcludedCourses
+	}
+	if update.MinSemester != nil {
+		course.MinSemester = *update.MinSemester
+	}
+	if update.MinCGPA != nil {
+		course.MinCGPA = *update.MinCGPA
+	}
+	if update.Sessions != nil {
+		course.Sessions = *update.Sessions
+	}
+}
+
+// checkQuotas checks that the course's department quotas only name its own
+// departments and fit within its seats.
+func checkQuotas(verr *domain.ValidationError, course *models.Course) {
+	for department, quota := range course.DepartmentQuotas {
+		if !course.HasDepartment(department) {
+			verr.Add("department_quotas["+department+"]", "must be one of the course's departments")
+		}
+		if quota < 0 {
+			verr.Add("department_quotas["+department+"]", "must be at least 0")
+		}
+	}
+	if total := course.QuotaTotal(); total > course.TotalSeats {
+		verr.Add("department_quotas", fmt.Sprintf("reserve %d seats but the course has only %d", total, course.TotalSeats))
+	}
+}
+
+func (s *courseService) checkCategoryExists(courseType int) error {
+	if courseType < 1 {
+		return domain.ErrCategoryNotFound
+	}
+	_, err := s.categoryRepo.GetByID(uint(courseType))
+	return notFoundAs(err, domain.ErrCategoryNotFound)
+}
+
+// normalizeSeat checks seatNo against the course's seat numbering, which runs
+// from 1 to TotalSeats, and returns it in canonical form (e.g. "07" -> "7").
+func normalizeSeat(course *models.Course, seatNo string) (string, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(seatNo))
+	if err != nil || n < 1 || n > course.TotalSeats {
+		return "", domain.ErrInvalidSeat.WithMessage(fmt.Sprintf("invalid seat number: seats are numbered 1 to %d", course.TotalSeats))
+	}
+	return strconv.Itoa(n), nil
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/sk/elective/src/internal/domain"
+	"gorm.io/gorm"
+)
+
+// notFoundAs replaces gorm.ErrRecordNotFound with the given domain error so
+// that storage errors do not leak to callers. Other errors pass through.
+func notFoundAs(err error, notFound *domain.Error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sk/elective/src/internal/config"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+type signingKey struct {
+	id        string
+	signKey   interface{} // nil for retired keys loaded from a public key
+	verifyKey interface{}
+}
+
+// keyring signs tokens with the active key and verifies them with any known
+// key, always pinned to a single algorithm.
+type keyring struct {
+	method jwt.SigningMethod
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+func newKeyring(cfg config.JWTConfig) (*keyring, error) {
+	method := jwt.GetSigningMethod(cfg.Algorithm)
+	if method == nil || (method != jwt.SigningMethodHS256 && method != jwt.SigningMethodRS256 && method != jwt.SigningMethodEdDSA) {
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+
+	keys := cfg.Keys
+	if len(keys) == 0 && method == jwt.SigningMethodHS256 {
+		keys = []config.JWTKey{{ID: cfg.ActiveKeyID, Material: cfg.Secret}}
+	}
+
+	ring := &keyring{method: method, keys: make(map[string]*signingKey)}
+	for _, k := range keys {
+		key, err := loadSigningKey(method, k)
+		if err != nil {
+			return nil, fmt.Errorf("loading JWT key %q: %w", k.ID, err)
+		}
+		ring.keys[k.ID] = key
+	}
+
+	ring.active = ring.keys[cfg.ActiveKeyID]
+	if ring.active == nil || ring.active.signKey == nil {
+		return nil, fmt.Errorf("active JWT key %q has no signing key", cfg.ActiveKeyID)
+	}
+
+	return ring, nil
+}
+
+func loadSigningKey(method jwt.SigningMethod, k config.JWTKey) (*signingKey, error) {
+	if method == jwt.SigningMethodHS256 {
+		if k.Material == "" {
+			return nil, errors.New("empty secret")
+		}
+		secret := []byte(k.Material)
+		return &signingKey{id: k.ID, signKey: secret, verifyKey: secret}, nil
+	}
+
+	pem, err := os.ReadFile(k.Material)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == jwt.SigningMethodRS256 {
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(pem); err == nil {
+			return &signingKey{id: k.ID, signKey: private, verifyKey: &private.PublicKey}, nil
+		}
+		public, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		return &signingKey{id: k.ID, verifyKey: public}, nil
+	}
+
+	if private, err := jwt.ParseEdPrivateKeyFromPEM(pem); err == nil {
+		return &signingKey{id: k.ID, signKey: private, verifyKey: private.(crypto.Signer).Public()}, nil
+	}
+	public, err := jwt.ParseEdPublicKeyFromPEM(pem)
+	if err != nil {
+		return nil, err
+	}
+	return &signingKey{id: k.ID, verifyKey: public}, nil
+}
+
+func (k *keyring) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.active.id
+	return token.SignedString(k.active.signKey)
+}
+
+// parse verifies tokenString into claims. Tokens must carry an expiry, so
+// that tokens issued without one before expiries were introduced are
+// refused rather than valid forever.
+func (k *keyring) parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, k.keyFunc,
+		jwt.WithValidMethods([]string{k.method.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+}
+
+// keyFunc picks the verification key named by the token's kid. Tokens issued
+// before key ids were introduced carry no kid and are checked against the
+// active key.
+func (k *keyring) keyFunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+	}
+
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return k.active.verifyKey, nil
+	}
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key.verifyKey, nil
+}
+
+// jwks lists the public keys of the ring. Shared HS256 secrets are never
+// published, so the list is empty in that mode.
+func (k *keyring) jwks() []models.JSONWebKey {
+	keys := []models.JSONWebKey{}
+	for _, key := range k.keys {
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, models.JSONWebKey{
+				KeyType:   "RSA",
+				KeyID:     key.id,
+				Use:       "sig",
+				Algorithm: k.method.Alg(),
+				N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, models.JSONWebKey{
+				KeyType:   "OKP",
+				KeyID:     key.id,
+				Use:       "sig",
+				Algorithm: k.method.Alg(),
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].KeyID < keys[j].KeyID })
+	return keys
+}
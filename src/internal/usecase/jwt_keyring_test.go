@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sk/elective/src/internal/config"
+)
+
+func TestKeyringParse(t *testing.T) {
+	ring, err := newKeyring(config.JWTConfig{Algorithm: "HS256", Secret: "test-secret", ActiveKeyID: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	// Tokens from before key ids and expiries carry neither
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		StudentID:        1,
+		RegisterNo:       "R1",
+		RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(now)},
+	})
+	legacyToken, err := legacy.SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(claims jwt.RegisteredClaims) string {
+		token, err := ring.sign(&Claims{StudentID: 1, RegisterNo: "R1", RegisteredClaims: claims})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{
+			name:  "current token",
+			token: sign(jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(now), ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour))}),
+			valid: true,
+		},
+		{
+			name:  "expired token",
+			token: sign(jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(now.Add(-2 * time.Hour)), ExpiresAt: jwt.NewNumericDate(now.Add(-time.Hour))}),
+		},
+		{
+			name:  "token without exp",
+			token: sign(jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(now)}),
+		},
+		{
+			name:  "legacy token without kid or exp",
+			token: legacyToken,
+		},
+		{
+			name:  "token issued in the future",
+			token: sign(jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(now.Add(time.Hour)), ExpiresAt: jwt.NewNumericDate(now.Add(2 * time.Hour))}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := ring.parse(tt.token, &Claims{})
+			valid := err == nil && token.Valid
+			if valid != tt.valid {
+				t.Errorf("valid = %v (err %v), want %v", valid, err, tt.valid)
+			}
+		})
+	}
+}
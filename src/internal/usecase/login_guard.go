@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+func accountLoginKey(registerNo string) string {
+	return "account:" + registerNo
+}
+
+func ipLoginKey(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginAllowed refuses a login attempt while the account or address is
+// locked, or while the delay earned by the account's previous failures is
+// running. Addresses get no delay: many students share one behind a campus
+// NAT, so their typos must not slow everyone else down.
+func (s *authService) checkLoginAllowed(registerNo, ip string) error {
+	now := time.Now()
+
+	account, err := s.getLoginAttempt(accountLoginKey(registerNo))
+	if err != nil {
+		return err
+	}
+	if account.IsLocked(now) {
+		return domain.ErrAccountLocked
+	}
+
+	address, err := s.getLoginAttempt(ipLoginKey(ip))
+	if err != nil {
+		return err
+	}
+	if address.IsLocked(now) {
+		return domain.ErrLoginThrottled
+	}
+
+	if now.Before(account.LastFailureAt.Add(s.loginDelay(account.Failures))) {
+		return domain.ErrLoginThrottled
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed login against both the account and the
+// address and locks whichever reached its threshold.
+func (s *authService) recordLoginFailure(registerNo, ip string) {
+	limits := map[string]int{
+		accountLoginKey(registerNo): s.authConfig.MaxFailedLogins,
+		ipLoginKey(ip):              s.authConfig.IPMaxFailedLogins,
+	}
+
+	for key, limit := range limits {
+		attempt, err := s.loginAttemptRepo.RecordFailure(key, s.authConfig.LockoutDuration)
+		if err != nil {
+			log.Printf("Failed to record login failure for %s: %v", key, err)
+			continue
+		}
+
+		if attempt.Failures >= limit {
+			if err := s.loginAttemptRepo.Lock(key, time.Now().Add(s.authConfig.LockoutDuration)); err != nil {
+				log.Printf("Failed to lock %s: %v", key, err)
+			}
+		}
+	}
+}
+
+func (s *authService) resetLoginFailures(registerNo string) {
+	if err := s.loginAttemptRepo.Reset(accountLoginKey(registerNo)); err != nil {
+		log.Printf("Failed to reset login failures for %s: %v", registerNo, err)
+	}
+}
+
+// loginDelay is how long to wait after the given number of consecutive
+// failures: nothing for the first failure, then LoginBaseDelay doubling with
+// each further one, capped at LockoutDuration.
+func (s *authService) loginDelay(failures int) time.Duration {
+	if failures < 2 || s.authConfig.LoginBaseDelay <= 0 {
+		return 0
+	}
+
+	delay := s.authConfig.LoginBaseDelay
+	for i := 2; i < failures && delay < s.authConfig.LockoutDuration; i++ {
+		delay *= 2
+	}
+	if delay > s.authConfig.LockoutDuration {
+		return s.authConfig.LockoutDuration
+	}
+	return delay
+}
+
+// getLoginAttempt returns an empty record for keys without failures.
+func (s *authService) getLoginAttempt(key string) (*models.LoginAttempt, error) {
+	attempt, err := s.loginAttemptRepo.GetByKey(key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.LoginAttempt{Key: key}, nil
+	}
+	return attempt, err
+}
+
+// GetLoginLock returns the failed login record of an account for admins.
+func (s *authService) GetLoginLock(registerNo string) (*models.LoginAttempt, error) {
+	return s.getLoginAttempt(accountLoginKey(registerNo))
+}
+
+// UnlockAccount clears the lock and the failure count of an account.
+func (s *authService) UnlockAccount(registerNo string) error {
+	return s.loginAttemptRepo.Reset(accountLoginKey(registerNo))
+}
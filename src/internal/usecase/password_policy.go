@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/sk/elective/src/internal/config"
+	"github.com/sk/elective/src/internal/domain"
+)
+
+// maxPasswordBytes is the most bcrypt will hash; longer passwords are
+// rejected by bcrypt rather than truncated.
+const maxPasswordBytes = 72
+
+// commonPasswords are rejected regardless of configuration.
+var commonPasswords = []string{
+	"123456", "12345678", "123456789", "1234567890", "password", "password1",
+	"password123", "qwerty", "qwerty123", "abc123", "111111", "123123",
+	"iloveyou", "admin", "admin123", "welcome", "welcome1", "letmein",
+	"monkey", "dragon", "football", "baseball", "sunshine", "princess",
+	"passw0rd", "p@ssw0rd", "changeme", "student", "student123", "college",
+}
+
+type passwordPolicy struct {
+	config.PasswordPolicyConfig
+	denylist map[string]struct{}
+}
+
+func newPasswordPolicy(cfg config.PasswordPolicyConfig) (*passwordPolicy, error) {
+	policy := &passwordPolicy{
+		PasswordPolicyConfig: cfg,
+		denylist:             make(map[string]struct{}),
+	}
+	for _, password := range commonPasswords {
+		policy.denylist[password] = struct{}{}
+	}
+
+	if cfg.DenylistFile == "" {
+		return policy, nil
+	}
+
+	f, err := os.Open(cfg.DenylistFile)
+	if err != nil {
+		return nil, fmt.Errorf("opening password denylist: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if password := strings.TrimSpace(scanner.Text()); password != "" {
+			policy.denylist[strings.ToLower(password)] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading password denylist: %w", err)
+	}
+
+	return policy, nil
+}
+
+// check returns a *domain.ValidationError listing every rule password breaks,
+// reported under field.
+func (p *passwordPolicy) check(field, password, registerNo string) error {
+	verr := domain.NewValidationError()
+
+	if len([]rune(password)) < p.MinLength {
+		verr.Add(field, fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		verr.Add(field, fmt.Sprintf("must be at most %d bytes long", maxPasswordBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		verr.Add(field, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		verr.Add(field, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		verr.Add(field, "must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		verr.Add(field, "must contain a symbol")
+	}
+
+	if _, ok := p.denylist[strings.ToLower(password)]; ok {
+		verr.Add(field, "is too common")
+	}
+
+	if registerNo != "" && strings.Contains(strings.ToLower(password), strings.ToLower(registerNo)) {
+		verr.Add(field, "must not contain your register number")
+	}
+
+	return verr.ErrOrNil()
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ForgotPassword sends a reset token to the student. Unknown register numbers
+// are ignored so the endpoint cannot be used to find out who is registered.
+// Requests are throttled per register number and per address, and earlier
+// tokens stay valid until they expire, so that nobody can keep a student's
+// reset link dead by requesting new ones.
+func (s *authService) ForgotPassword(registerNo, ip string) error {
+	if err := s.throttleResetRequest(registerNo, ip); err != nil {
+		return err
+	}
+
+	student, err := s.studentRepo.GetByRegisterNo(registerNo)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(s.authConfig.PasswordResetTTL)
+
+	err = s.uow.Do(func(repos *domain.Repositories) error {
+		return repos.PasswordResetTokens.Create(&models.PasswordResetToken{
+			StudentID: student.ID,
+			TokenHash: hashToken(token),
+			ExpiresAt: expiresAt,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.notifier.SendPasswordReset(student, token, expiresAt); err != nil {
+		log.Printf("Failed to send password reset to %s: %v", student.RegisterNo, err)
+	}
+	return nil
+}
+
+// throttleResetRequest counts a reset request against the register number and
+// the address, whether or not the register number exists, and refuses it once
+// either has made too many within PasswordResetTTL.
+func (s *authService) throttleResetRequest(registerNo, ip string) error {
+	limits := map[string]int{
+		"reset:account:" + registerNo: s.authConfig.MaxResetRequests,
+		"reset:ip:" + ip:              s.authConfig.IPMaxResetRequests,
+	}
+
+	for key, limit := range limits {
+		attempt, err := s.loginAttemptRepo.RecordFailure(key, s.authConfig.PasswordResetTTL)
+		if err != nil {
+			return err
+		}
+		if attempt.Failures > limit {
+			return domain.ErrResetThrottled
+		}
+	}
+	return nil
+}
+
+// ResetPassword redeems a reset token and sets a new password.
+func (s *authService) ResetPassword(token, newPassword string) error {
+	return s.uow.Do(func(repos *domain.Repositories) error {
+		stored, err := repos.PasswordResetTokens.GetByHashForUpdate(hashToken(token))
+		if err != nil {
+			return notFoundAs(err, domain.ErrInvalidResetToken)
+		}
+
+		if stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+			return domain.ErrInvalidResetToken
+		}
+
+		student, err := repos.Students.GetByIDForUpdate(stored.StudentID)
+		if err != nil {
+			return err
+		}
+
+		if err := repos.PasswordResetTokens.MarkUsed(stored.ID); err != nil {
+			return err
+		}
+
+		return s.setPassword(repos, student, newPassword)
+	})
+}
+
+// ChangePassword replaces the password of a logged in student after checking
+// the current one.
+func (s *authService) ChangePassword(studentID uint, currentPassword, newPassword string) error {
+	return s.uow.Do(func(repos *domain.Repositories) error {
+		student, err := repos.Students.GetByIDForUpdate(studentID)
+		if err != nil {
+			return notFoundAs(err, domain.ErrStudentNotFound)
+		}
+
+		err = bcrypt.CompareHashAndPassword([]byte(student.Password), []byte(currentPassword))
+		if err != nil {
+			return domain.ErrWrongPassword
+		}
+
+		return s.setPassword(repos, student, newPassword)
+	})
+}
+
+// setPassword stores the new password hash and ends every existing session:
+// refresh tokens are revoked and access tokens issued earlier stop validating.
+func (s *authService) setPassword(repos *domain.Repositories, student *models.Student, newPassword string) error {
+	if err := s.passwordPolicy.check("new_password", newPassword, student.RegisterNo); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	student.Password = string(hashedPassword)
+	student.PasswordChangedAt = &now
+	if err := repos.Students.Update(student); err != nil {
+		return err
+	}
+
+	// Reset links sent for the old password must not outlive it
+	if err := repos.PasswordResetTokens.InvalidateByStudentID(student.ID); err != nil {
+		return err
+	}
+
+	return repos.RefreshTokens.RevokeByStudentID(student.ID)
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+// rosterColumns are the CSV columns an import must provide, in any order.
+// The rosterOptionalColumns set each student's current semester and CGPA;
+// imports without them leave those of listed students unchanged.
+var (
+	rosterColumns         = []string{"register_no", "name", "department", "batch"}
+	rosterOptionalColumns = []string{"semester", "cgpa"}
+)
+
+const (
+	maxSemester = 12
+	maxCGPA     = 10
+)
+
+type rosterService struct {
+	rosterRepo  domain.RosterRepository
+	uow         domain.UnitOfWork
+	departments map[string]struct{}
+}
+
+func NewRosterService(rosterRepo domain.RosterRepository, uow domain.UnitOfWork, departments []string) domain.RosterService {
+	known := make(map[string]struct{}, len(departments))
+	for _, department := range departments {
+		known[department] = struct{}{}
+	}
+
+	return &rosterService{
+		rosterRepo:  rosterRepo,
+		uow:         uow,
+		departments: known,
+	}
+}
+
+func (s *rosterService) GetRoster() ([]models.RosterEntry, error) {
+	return s.rosterRepo.GetAll()
+}
+
+// ImportCSV adds or updates roster entries from a CSV file with a header row.
+// The import is all or nothing: if any row is invalid nothing is stored and
+// the problems are reported per line. Students who already registered pick
+// up their new department and batch, and their semester and CGPA when the
+// file has those columns.
+func (s *rosterService) ImportCSV(r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, domain.ErrInvalidBody.WithMessage("roster CSV must start with a header row")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	verr := domain.NewValidationError()
+	for _, name := range rosterColumns {
+		if _, ok := columns[name]; !ok {
+			verr.Add("header", fmt.Sprintf("missing column %q", name))
+		}
+	}
+	if err := verr.ErrOrNil(); err != nil {
+		return 0, err
+	}
+
+	var optional []string
+	for _, name := range rosterOptionalColumns {
+		if _, ok := columns[name]; ok {
+			optional = append(optional, name)
+		}
+	}
+
+	var entries []models.RosterEntry
+	seen := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return 0, err
+			}
+			verr.Add(fmt.Sprintf("line %d", parseErr.Line), parseErr.Err.Error())
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		field := fmt.Sprintf("line %d", line)
+
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		entry := models.RosterEntry{
+			RegisterNo: get("register_no"),
+			Name:       get("name"),
+			Department: strings.ToUpper(get("department")),
+		}
+
+		if entry.RegisterNo == "" {
+			verr.Add(field, "register_no is required")
+		} else if first, ok := seen[entry.RegisterNo]; ok {
+			verr.Add(field, fmt.Sprintf("register_no %s already appears on line %d", entry.RegisterNo, first))
+		} else {
+			seen[entry.RegisterNo] = line
+		}
+
+		if _, ok := s.departments[entry.Department]; !ok {
+			verr.Add(field, fmt.Sprintf("unknown department %q", entry.Department))
+		}
+
+		batch, err := strconv.Atoi(get("batch"))
+		if err != nil || batch < 1900 || batch > 2999 {
+			verr.Add(field, "batch must be a year of admission such as 2023")
+		}
+		entry.Batch = batch
+
+		if value := get("semester"); value != "" {
+			semester, err := strconv.Atoi(value)
+			if err != nil || semester < 1 || semester > maxSemester {
+				verr.Add(field, fmt.Sprintf("semester must be between 1 and %d", maxSemester))
+			}
+			entry.Semester = semester
+		}
+
+		if value := get("cgpa"); value != "" {
+			cgpa, err := strconv.ParseFloat(value, 64)
+			if err != nil || cgpa < 0 || cgpa > maxCGPA {
+				verr.Add(field, fmt.Sprintf("cgpa must be between 0 and %d", maxCGPA))
+			}
+			entry.CGPA = cgpa
+		}
+
+		entries = append(entries, entry)
+	}
+	if err := verr.ErrOrNil(); err != nil {
+		return 0, err
+	}
+
+	registerNos := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		registerNos = append(registerNos, entry.RegisterNo)
+	}
+
+	err = s.uow.Do(func(repos *domain.Repositories) error {
+		if err := repos.Roster.Upsert(entries, optional); err != nil {
+			return err
+		}
+		return repos.Roster.SyncStudents(registerNos, optional)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(entries), nil
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+const (
+	SeatStrategyLowest          = "lowest"
+	SeatStrategyRandom          = "random"
+	SeatStrategyDepartmentBlock = "department-block"
+)
+
+// seatAssigner picks a free seat for a student who did not choose one.
+type seatAssigner func(course *models.Course, student *models.Student) (string, error)
+
+func newSeatAssigner(strategy string) (seatAssigner, error) {
+	switch strategy {
+	case "", SeatStrategyLowest:
+		return assignLowestSeat, nil
+	case SeatStrategyRandom:
+		return assignRandomSeat, nil
+	case SeatStrategyDepartmentBlock:
+		return assignDepartmentBlockSeat, nil
+	default:
+		return nil, fmt.Errorf("unknown seat assignment strategy %q", strategy)
+	}
+}
+
+func freeSeats(course *models.Course, from, to int) []string {
+	var seats []string
+	for n := from; n <= to; n++ {
+		seat := strconv.Itoa(n)
+		if !course.IsSeatBooked(seat) {
+			seats = append(seats, seat)
+		}
+	}
+	return seats
+}
+
+func assignLowestSeat(course *models.Course, _ *models.Student) (string, error) {
+	seats := freeSeats(course, 1, course.TotalSeats)
+	if len(seats) == 0 {
+		return "", domain.ErrCourseFull
+	}
+	return seats[0], nil
+}
+
+func assignRandomSeat(course *models.Course, _ *models.Student) (string, error) {
+	seats := freeSeats(course, 1, course.TotalSeats)
+	if len(seats) == 0 {
+		return "", domain.ErrCourseFull
+	}
+	return seats[rand.Intn(len(seats))], nil
+}
+
+// assignDepartmentBlockSeat splits the seats into equal consecutive blocks,
+// one per department in course.Departments, and seats the student in their
+// department's block. It falls back to the lowest free seat once that block
+// is full or when the department is not listed.
+func assignDepartmentBlockSeat(course *models.Course, student *models.Student) (string, error) {
+	for i, department := range course.Departments {
+		if department != student.Department {
+			continue
+		}
+		size := (course.TotalSeats + len(course.Departments) - 1) / len(course.Departments)
+		to := (i + 1) * size
+		if to > course.TotalSeats {
+			to = course.TotalSeats
+		}
+		if seats := freeSeats(course, i*size+1, to); len(seats) > 0 {
+			return seats[0], nil
+		}
+		break
+	}
+	return assignLowestSeat(course, student)
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type termService struct {
+	termRepo   domain.AcademicTermRepository
+	windowRepo domain.BookingWindowRepository
+	phaseRepo  domain.BookingPhaseRepository
+	uow        domain.UnitOfWork
+}
+
+func NewTermService(termRepo domain.AcademicTermRepository, windowRepo domain.BookingWindowRepository, phaseRepo domain.BookingPhaseRepository, uow domain.UnitOfWork) domain.TermService {
+	return &termService{
+		termRepo:   termRepo,
+		windowRepo: windowRepo,
+		phaseRepo:  phaseRepo,
+		uow:        uow,
+	}
+}
+
+func (s *termService) GetTerms() ([]models.AcademicTerm, error) {
+	return s.termRepo.GetAll()
+}
+
+// CreateTerm adds a term. Creating an active term deactivates the previous
+// one.
+func (s *termService) CreateTerm(term *models.AcademicTerm) error {
+	if term.AllocationMode == "" {
+		term.AllocationMode = models.AllocationFirstCome
+	}
+	if err := checkTermDates(term); err != nil {
+		return err
+	}
+	if err := checkAllocationMode(term.AllocationMode); err != nil {
+		return err
+	}
+
+	return s.uow.Do(func(repos *domain.Repositories) error {
+		if err := checkTermName(repos, term.Name, 0); err != nil {
+			return err
+		}
+		if err := repos.Terms.Create(term); err != nil {
+			return err
+		}
+		if term.Active {
+			return repos.Terms.Deactivate(term.ID)
+		}
+		return nil
+	})
+}
+
+func (s *termService) UpdateTerm(termID uint, update *models.AcademicTermUpdate) (*models.AcademicTerm, error) {
+	var term *models.AcademicTerm
+	err := s.uow.Do(func(repos *domain.Repositories) error {
+		var err error
+		term, err = repos.Terms.GetByID(termID)
+		if err != nil {
+			return notFoundAs(err, domain.ErrTermNotFound)
+		}
+
+		if update.Name != nil {
+			if err := checkTermName(repos, *update.Name, term.ID); err != nil {
+				return err
+			}
+			term.Name = *update.Name
+		}
+		if update.StartsAt != nil {
+			term.StartsAt = *update.StartsAt
+		}
+		if update.EndsAt != nil {
+			term.EndsAt = *update.EndsAt
+		}
+		if update.Active != nil {
+			term.Active = *update.Active
+		}
+		if update.AllocationMode != nil && *update.AllocationMode != term.AllocationMode {
+			if term.AllocatedAt != nil {
+				return domain.ErrAllocationDone
+			}
+			if err := checkAllocationMode(*update.AllocationMode); err != nil {
+				return err
+			}
+			term.AllocationMode = *update.AllocationMode
+		}
+		update.DropDeadline.Apply(&term.DropDeadline)
+
+		if err := checkTermDates(term); err != nil {
+			return err
+		}
+		if err := repos.Terms.Update(term); err != nil {
+			return err
+		}
+		if term.Active {
+			return repos.Terms.Deactivate(term.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+func (s *termService) GetWindows(termID uint) ([]models.BookingWindow, error) {
+	if _, err := s.termRepo.GetByID(termID); err != nil {
+		return nil, notFoundAs(err, domain.ErrTermNotFound)
+	}
+	return s.windowRepo.GetByTermID(termID)
+}
+
+func (s *termService) CreateWindow(window *models.BookingWindow) error {
+	if !window.ClosesAt.After(window.OpensAt) {
+		verr := domain.NewValidationError()
+		verr.Add("closes_at", "must be after opens_at")
+		return verr
+	}
+
+	if _, err := s.termRepo.GetByID(window.TermID); err != nil {
+		return notFoundAs(err, domain.ErrTermNotFound)
+	}
+	return s.windowRepo.Create(window)
+}
+
+func (s *termService) DeleteWindow(termID uint, windowID uint) error {
+	return notFoundAs(s.windowRepo.Delete(termID, windowID), domain.ErrWindowNotFound)
+}
+
+func (s *termService) GetPhases(termID uint) ([]models.BookingPhase, error) {
+	if _, err := s.termRepo.GetByID(termID); err != nil {
+		return nil, notFoundAs(err, domain.ErrTermNotFound)
+	}
+	return s.phaseRepo.GetByTermID(termID)
+}
+
+func (s *termService) CreatePhase(phase *models.BookingPhase) error {
+	if _, err := s.termRepo.GetByID(phase.TermID); err != nil {
+		return notFoundAs(err, domain.ErrTermNotFound)
+	}
+	return s.phaseRepo.Create(phase)
+}
+
+func (s *termService) DeletePhase(termID uint, phaseID uint) error {
+	return notFoundAs(s.phaseRepo.Delete(termID, phaseID), domain.ErrPhaseNotFound)
+}
+
+func checkTermDates(term *models.AcademicTerm) error {
+	if !term.EndsAt.After(term.StartsAt) {
+		verr := domain.NewValidationError()
+		verr.Add("ends_at", "must be after starts_at")
+		return verr
+	}
+	return nil
+}
+
+func checkAllocationMode(mode string) error {
+	if mode != models.AllocationFirstCome && mode != models.AllocationPreference {
+		return domain.ErrInvalidAllocationMode
+	}
+	return nil
+}
+
+// checkTermName rejects a name already used by a term other than selfID.
+func checkTermName(repos *domain.Repositories, name string, selfID uint) error {
+	existing, err := repos.Terms.GetByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != selfID {
+		return domain.ErrTermExists
+	}
+	return nil
+}
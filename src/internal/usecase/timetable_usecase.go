@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+// GetTimetable returns the student's weekly timetable for a term. termID 0
+// means the active term, or the courses outside any term when none is active.
+func (s *courseService) GetTimetable(studentID uint, termID uint) (*models.Timetable, error) {
+	var err error
+	if termID == 0 {
+		termID, err = s.activeTermID()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	timetable := &models.Timetable{Entries: []models.TimetableEntry{}}
+	if termID != 0 {
+		timetable.Term, err = s.termRepo.GetByID(termID)
+		if err != nil {
+			return nil, notFoundAs(err, domain.ErrTermNotFound)
+		}
+	}
+
+	bookings, err := s.bookingRepo.GetByStudentID(studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, booking := range bookings {
+		if booking.TermID != termID || booking.Course.DeletedAt.Valid {
+			continue
+		}
+		for _, session := range booking.Course.SectionSessions(booking.Section) {
+			// Shared sessions are listed under the student's section
+			session.Section = booking.Section
+			timetable.Entries = append(timetable.Entries, models.TimetableEntry{
+				Session:    session,
+				CourseID:   booking.CourseID,
+				CourseName: booking.Course.Name,
+				SeatNo:     booking.SeatNo,
+			})
+		}
+	}
+
+	sort.SliceStable(timetable.Entries, func(i, j int) bool {
+		a, b := timetable.Entries[i], timetable.Entries[j]
+		if a.DayIndex() != b.DayIndex() {
+			return a.DayIndex() < b.DayIndex()
+		}
+		aStart, _, _ := a.Minutes()
+		bStart, _, _ := b.Minutes()
+		return aStart < bStart
+	})
+
+	return timetable, nil
+}
+
+// pickSection chooses the section of course to book: the requested one, or
+// else the first section that fits the student's timetable. It returns "" for
+// courses without sections. Either way the section must not meet at the same
+// time as another course the student has booked in the same term, whatever
+// its category.
+func pickSection(bookingRepo domain.CourseBookingRepository, studentID uint, course *models.Course, requested string) (string, error) {
+	candidates := course.Sections()
+	if requested != "" {
+		if !containsString(candidates, requested) {
+			return "", domain.ErrInvalidSection.WithMessage(fmt.Sprintf("%s has no section %q", course.Name, requested))
+		}
+		candidates = []string{requested}
+	}
+	if len(candidates) == 0 {
+		candidates = []string{""}
+	}
+	if len(course.Sessions) == 0 {
+		return candidates[0], nil
+	}
+
+	bookings, err := bookingRepo.GetByStudentID(studentID)
+	if err != nil {
+		return "", err
+	}
+
+	var clash error
+	for _, section := range candidates {
+		if err := checkClash(bookings, course, section); err == nil {
+			return section, nil
+		} else if clash == nil {
+			clash = err
+		}
+	}
+	return "", clash
+}
+
+// checkClash checks section of course against the student's bookings.
+func checkClash(bookings []models.CourseBooking, course *models.Course, section string) error {
+	for i := range bookings {
+		booked := &bookings[i].Course
+		if bookings[i].TermID != course.TermID || booked.ID == course.ID || booked.DeletedAt.Valid {
+			continue
+		}
+		if session, other, ok := course.ClashWith(section, booked, bookings[i].Section); ok {
+			return domain.ErrTimetableClash.WithMessage(fmt.Sprintf("%s (%s) clashes with %s (%s), which you have booked", course.Name, session, booked.Name, other))
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSessions checks the days and times of the course's sessions and that
+// no section has overlapping sessions.
+func checkSessions(verr *domain.ValidationError, course *models.Course) {
+	for i, session := range course.Sessions {
+		field := fmt.Sprintf("sessions[%d]", i)
+		if session.DayIndex() < 0 {
+			verr.Add(field+".day", "must be a day of the week, e.g. monday")
+		}
+
+		start, end, ok := session.Minutes()
+		if !ok {
+			verr.Add(field, "start and end must be times in 15:04 format")
+			continue
+		}
+		if start >= end {
+			verr.Add(field+".end", "must be after start")
+			continue
+		}
+
+		for j := 0; j < i; j++ {
+			if session.SharedWith(course.Sessions[j]) && session.Overlaps(course.Sessions[j]) {
+				verr.Add(field, fmt.Sprintf("overlaps sessions[%d]", j))
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+// JoinWaitlist queues the student for a full course, or one whose free seats
+// are all held for other departments, and returns their position in the
+// queue. Only students who could book the course once a seat frees up, and
+// whose booking window is open, may join.
+func (s *courseService) JoinWaitlist(studentID uint, courseID uint) (int64, error) {
+	now := time.Now()
+	if err := s.releaseQuota(courseID, now); err != nil {
+		return 0, err
+	}
+
+	var position int64
+	err := s.uow.Do(func(repos *domain.Repositories) error {
+		student, err := repos.Students.GetByIDForUpdate(studentID)
+		if err != nil {
+			return notFoundAs(err, domain.ErrStudentNotFound)
+		}
+
+		course, err := repos.Courses.GetByIDForUpdate(courseID)
+		if err != nil {
+			return notFoundAs(err, domain.ErrCourseNotFound)
+		}
+
+		if err := checkNotAllocating(repos, course.TermID); err != nil {
+			return err
+		}
+		if err := checkBookingWindow(repos, student, course.TermID, now); err != nil {
+			return err
+		}
+
+		if !course.IsFull() {
+			err = checkDepartmentQuota(repos, student, course, now)
+			if err == nil {
+				return domain.ErrCourseNotFull
+			}
+			if !errors.Is(err, domain.ErrDepartmentQuotaFull) {
+				return err
+			}
+		}
+
+		if err := checkEligible(repos, student, course); err != nil {
+			return err
+		}
+		if _, err := pickSection(repos.Bookings, studentID, course, ""); err != nil {
+			return err
+		}
+
+		if _, err := repos.Waitlists.GetByStudentAndCourse(studentID, courseID); err == nil {
+			return domain.ErrAlreadyWaitlisted
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		entry := &models.Waitlist{
+			StudentID: studentID,
+			CourseID:  courseID,
+		}
+		if err := repos.Waitlists.Create(entry); err != nil {
+			return err
+		}
+
+		position, err = repos.Waitlists.Position(entry)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return position, nil
+}
+
+func (s *courseService) LeaveWaitlist(studentID uint, courseID uint) error {
+	err := s.waitlistRepo.Delete(studentID, courseID)
+	return notFoundAs(err, domain.ErrNotWaitlisted)
+}
+
+// GetWaitlistPosition returns the student's position in the course's
+// waitlist and the total length of the waitlist.
+func (s *courseService) GetWaitlistPosition(studentID uint, courseID uint) (int64, int64, error) {
+	entry, err := s.waitlistRepo.GetByStudentAndCourse(studentID, courseID)
+	if err != nil {
+		return 0, 0, notFoundAs(err, domain.ErrNotWaitlisted)
+	}
+
+	position, err := s.waitlistRepo.Position(entry)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	length, err := s.waitlistRepo.CountByCourseID(courseID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return position, length, nil
+}
+
+// promoteWaitlist fills free seats of course, which must be locked by the
+// caller's transaction, from the head of its waitlist. Students who cannot
+// book it right now, for instance because their booking window has not
+// opened, their category quota is used up or their department's seats are
+// taken, or who are busy in another booking transaction, keep their place and
+// are skipped. Nobody is promoted while the course's term waits for a
+// preference allocation.
+func (s *courseService) promoteWaitlist(repos *domain.Repositories, course *models.Course) error {
+	if course.IsFull() {
+		return nil
+	}
+	if err := checkNotAllocating(repos, course.TermID); err != nil {
+		if errors.Is(err, domain.ErrPreferenceAllocation) {
+			return nil
+		}
+		return err
+	}
+
+	entries, err := repos.Waitlists.GetByCourseID(course.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, entry := range entries {
+		if course.IsFull() {
+			break
+		}
+
+		student, err := repos.Students.GetByIDForUpdateSkipLocked(entry.StudentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+
+		// Students queued before their window opened must not jump ahead of
+		// those already booking. placeBooking makes its checks before writing
+		// anything, so a refusal leaves the transaction clean.
+		err = checkBookingWindow(repos, student, course.TermID, now)
+		if err == nil {
+			_, err = placeBooking(repos, s.assignSeat, student, course, "", "")
+		}
+		if err != nil {
+			var domainErr *domain.Error
+			if errors.As(err, &domainErr) {
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
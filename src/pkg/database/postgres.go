@@ -27,9 +27,68 @@ func NewPostgresConnection(cfg config.DataBaseConfig) (*gorm.DB, error) {
 }
 
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&models.Student{},
+		&models.RosterEntry{},
+		&models.ElectiveCategory{},
+		&models.AcademicTerm{},
+		&models.BookingWindow{},
+		&models.BookingPhase{},
 		&models.Course{},
 		&models.CourseBooking{},
+		&models.Waitlist{},
+		&models.CoursePreference{},
+		&models.RefreshToken{},
+		&models.PasswordResetToken{},
+		&models.LoginAttempt{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := seedElectiveCategories(db); err != nil {
+		return err
+	}
+
+	return createCourseIndexes(db)
+}
+
+// seedElectiveCategories creates categories 1 and 2 on a fresh database so
+// that courses created with the former fixed course types keep working.
+func seedElectiveCategories(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.ElectiveCategory{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	categories := []models.ElectiveCategory{
+		{ID: 1, Name: "Professional Elective", MaxSelections: 1, Semesters: models.IntArray{}},
+		{ID: 2, Name: "Open Elective", MaxSelections: 1, Semesters: models.IntArray{}},
+	}
+	if err := db.Create(&categories).Error; err != nil {
+		return fmt.Errorf("failed to seed elective categories: %w", err)
+	}
+
+	// Explicit ids do not advance the sequence
+	return db.Exec(`SELECT setval(pg_get_serial_sequence('elective_categories', 'id'), (SELECT MAX(id) FROM elective_categories))`).Error
+}
+
+// createCourseIndexes adds the indexes behind course listing search and
+// filters, which AutoMigrate cannot express. The search expression must match
+// the one used by the course repository.
+func createCourseIndexes(db *gorm.DB) error {
+	statements := []string{
+		`CREATE INDEX IF NOT EXISTS idx_courses_search ON courses USING GIN (to_tsvector('english', coalesce(name, '') || ' ' || coalesce(description, '')))`,
+		`CREATE INDEX IF NOT EXISTS idx_courses_genres ON courses USING GIN (genres jsonb_path_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_courses_departments ON courses USING GIN (departments jsonb_path_ops)`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to create course indexes: %w", err)
+		}
+	}
+	return nil
 }
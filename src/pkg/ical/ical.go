@@ -0,0 +1,104 @@
+// Package ical writes iCalendar (RFC 5545) files with weekly recurring
+// events, enough for calendar apps to import a timetable.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event times are written as floating local times, so 10:00 stays 10:00 in
+// whichever time zone the calendar app is set to.
+const (
+	dateTimeLayout = "20060102T150405"
+	stampLayout    = "20060102T150405Z"
+)
+
+type Event struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Start       time.Time
+	End         time.Time
+
+	// Weekly repeats the event every week, until Until if it is set.
+	Weekly bool
+	Until  time.Time
+}
+
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Encode renders the calendar with CRLF line endings and long lines folded.
+func (c *Calendar) Encode(now time.Time) []byte {
+	var buf bytes.Buffer
+	w := func(name, value string) {
+		writeLine(&buf, name+":"+value)
+	}
+
+	w("BEGIN", "VCALENDAR")
+	w("VERSION", "2.0")
+	w("PRODID", escape(c.ProdID))
+	w("CALSCALE", "GREGORIAN")
+	if c.Name != "" {
+		w("X-WR-CALNAME", escape(c.Name))
+	}
+
+	stamp := now.UTC().Format(stampLayout)
+	for _, event := range c.Events {
+		w("BEGIN", "VEVENT")
+		w("UID", escape(event.UID))
+		w("DTSTAMP", stamp)
+		w("DTSTART", event.Start.Format(dateTimeLayout))
+		w("DTEND", event.End.Format(dateTimeLayout))
+		if event.Weekly {
+			rule := "FREQ=WEEKLY"
+			if !event.Until.IsZero() {
+				rule += ";UNTIL=" + event.Until.Format(dateTimeLayout)
+			}
+			w("RRULE", rule)
+		}
+		w("SUMMARY", escape(event.Summary))
+		if event.Location != "" {
+			w("LOCATION", escape(event.Location))
+		}
+		if event.Description != "" {
+			w("DESCRIPTION", escape(event.Description))
+		}
+		w("END", "VEVENT")
+	}
+
+	w("END", "VCALENDAR")
+	return buf.Bytes()
+}
+
+// writeLine folds lines longer than 75 octets, without splitting a UTF-8
+// sequence, by continuing them on lines that start with a space.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !startsRune(line[cut]) {
+			cut--
+		}
+		fmt.Fprintf(buf, "%s\r\n ", line[:cut])
+		line = line[cut:]
+		// Continuation lines lose one octet to the leading space
+		limit = 74
+	}
+	buf.WriteString(line + "\r\n")
+}
+
+func startsRune(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
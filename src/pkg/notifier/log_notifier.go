@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+// logNotifier is a stand-in for a real mail or SMS gateway during local
+// development. It writes each message to the log, or appends it to a file
+// when one is configured.
+type logNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewLogNotifier(path string) domain.Notifier {
+	return &logNotifier{path: path}
+}
+
+func (n *logNotifier) SendPasswordReset(student *models.Student, token string, expiresAt time.Time) error {
+	return n.write(fmt.Sprintf(
+		"password reset for %s (%s): token=%s expires=%s",
+		student.RegisterNo, student.Name, token, expiresAt.Format(time.RFC3339),
+	))
+}
+
+func (n *logNotifier) write(message string) error {
+	if n.path == "" {
+		log.Println("[notifier]", message)
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s %s\n", time.Now().Format(time.RFC3339), message)
+	return err
+}
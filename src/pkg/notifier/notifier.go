@@ -0,0 +1,36 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sk/elective/src/internal/config"
+	"github.com/sk/elective/src/internal/domain"
+	"github.com/sk/elective/src/internal/repository/models"
+)
+
+// New returns the notifier cfg asks for. The log notifier hands out live
+// reset tokens to whoever can read the log, so it is refused outside
+// development.
+func New(cfg config.NotifierConfig, development bool) (domain.Notifier, error) {
+	switch cfg.Driver {
+	case "log":
+		if !development {
+			return nil, errors.New(`the log notifier is only allowed with APP_ENV=development, set NOTIFIER=none to run without notifications`)
+		}
+		return NewLogNotifier(cfg.OutboxFile), nil
+	case "none":
+		return disabledNotifier{}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", cfg.Driver)
+	}
+}
+
+// disabledNotifier refuses every message, for deployments without a mail or
+// SMS gateway.
+type disabledNotifier struct{}
+
+func (disabledNotifier) SendPasswordReset(student *models.Student, token string, expiresAt time.Time) error {
+	return errors.New("notifications are disabled")
+}